@@ -0,0 +1,44 @@
+package main
+
+import (
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+func runExport(a *app, args []string) error {
+	fs := a.flagSet("export", "export [-from дата] [-to дата] [-kind steps|trainings] [-type тип]",
+		"Выводит записи из истории в выбранном формате.")
+	var q queryFlags
+	q.register(fs)
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	query, err := q.query(nil)
+	if err != nil {
+		return err
+	}
+
+	store, err := history.Open(a.historyPath())
+	if err != nil {
+		return err
+	}
+	entries, err := store.Query(query)
+	if err != nil {
+		return err
+	}
+
+	results := make([]report.Result, 0, len(entries))
+	for _, e := range entries {
+		results = append(results, e.Result)
+	}
+	return report.Write(a.stdout, a.format, results)
+}
+
+// lookupActivity возвращает название типа тренировки из реестра
+// по названию или псевдониму.
+func lookupActivity(name string) (string, bool) {
+	activity, ok := trainings.Lookup(name)
+	return activity.Name, ok
+}
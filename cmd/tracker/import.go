@@ -0,0 +1,301 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"FINAL-PROJECT-5/internal/actioninfo"
+	"FINAL-PROJECT-5/internal/daysteps"
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/profile"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+// Виды входных записей.
+const (
+	kindSteps     = "steps"
+	kindTrainings = "trainings"
+)
+
+// recordParser разбирает записи одного вида. ActionInfo рассчитывает
+// запись один раз и запоминает её как запись истории, которую
+// возвращает entry.
+type recordParser interface {
+	actioninfo.DataParser
+	entry() history.Entry
+}
+
+type stepsParser struct {
+	daysteps.DaySteps
+	input string
+	last  history.Entry
+}
+
+func (p *stepsParser) Parse(datastring string) error {
+	p.input = datastring
+	return p.DaySteps.Parse(datastring)
+}
+
+func (p *stepsParser) ActionInfo() (string, error) {
+	var err error
+	if p.last, err = history.FromDaySteps(p.input, p.DaySteps); err != nil {
+		return "", err
+	}
+	return report.Text(p.last.Result), nil
+}
+
+func (p *stepsParser) entry() history.Entry {
+	return p.last
+}
+
+type trainingsParser struct {
+	trainings.Training
+	input string
+	last  history.Entry
+}
+
+func (p *trainingsParser) Parse(datastring string) error {
+	p.input = datastring
+	return p.Training.Parse(datastring)
+}
+
+func (p *trainingsParser) ActionInfo() (string, error) {
+	var err error
+	if p.last, err = history.FromTraining(p.input, p.Training); err != nil {
+		return "", err
+	}
+	return report.Text(p.last.Result), nil
+}
+
+func (p *trainingsParser) entry() history.Entry {
+	return p.last
+}
+
+// newRecordParser возвращает парсер записей вида kind, проверяющий
+// правдоподобие записей по правилам rules.
+func newRecordParser(kind string, person personaldata.Personal, opts record.ParseOptions, rules *plausibility.Rules) (recordParser, error) {
+	switch kind {
+	case kindSteps:
+		return &stepsParser{DaySteps: daysteps.DaySteps{Personal: person, ParseOptions: opts, Plausibility: rules}}, nil
+	case kindTrainings:
+		return &trainingsParser{Training: trainings.Training{Personal: person, ParseOptions: opts, Plausibility: rules}}, nil
+	}
+	return nil, usageError{err: i18n.Errorf("неизвестный вид записей: %q (ожидается %s или %s)", kind, kindSteps, kindTrainings)}
+}
+
+// modeFlag добавляет в fs флаг -mode с режимом разбора записей.
+func modeFlag(fs *flag.FlagSet) *string {
+	return fs.String("mode", string(record.Default), "режим разбора: по умолчанию — как прежде для каждого вида записей,\n"+
+		"strict — точно по формату, lenient — допускает пробелы,\n"+
+		"разделители разрядов в количестве шагов, тип тренировки в любом регистре и лишние поля")
+}
+
+// parseOptions возвращает настройки разбора для значения флага -mode.
+func parseOptions(mode string) (record.ParseOptions, error) {
+	m, err := record.ParseMode(mode)
+	if err != nil {
+		return record.ParseOptions{}, usageError{err: err}
+	}
+	return record.ParseOptions{Mode: m}, nil
+}
+
+// implausibleFlag добавляет в fs флаг -implausible, задающий действие
+// для всех проверок правдоподобия.
+func implausibleFlag(fs *flag.FlagSet) *string {
+	return fs.String("implausible", "", "что делать с неправдоподобными записями (слишком высокий темп или скорость,\n"+
+		"слишком долгая запись): flag — принять с предупреждением, reject — отклонить, off — не проверять;\n"+
+		"по умолчанию — как задано в разделе plausibility файла настроек")
+}
+
+// plausibilityRules возвращает правила проверки правдоподобия из файла
+// настроек или правила по умолчанию, если файла нет. Непустое action
+// задаёт действие для всех проверок.
+func (a *app) plausibilityRules(action string) (*plausibility.Rules, error) {
+	rules := plausibility.DefaultRules()
+	c, err := a.loadConfig()
+	switch {
+	case err == nil:
+		rules = c.Rules()
+	case !errors.Is(err, profile.ErrNotFound):
+		return nil, err
+	}
+
+	if action != "" {
+		act, err := plausibility.ParseAction(action)
+		if err != nil {
+			return nil, usageError{err: err}
+		}
+		rules = rules.WithAction(act)
+	}
+	return &rules, nil
+}
+
+// warn выводит в поток ошибок предупреждения о неправдоподобных
+// показателях записи input и сообщает, были ли они.
+func (a *app) warn(input string, r report.Result) bool {
+	for _, w := range r.Warnings {
+		fmt.Fprintln(a.stderr, i18n.T("Предупреждение для записи '%s': %v", input, w))
+	}
+	return len(r.Warnings) > 0
+}
+
+// importBatch — сколько записей import накапливает перед дозаписью
+// в историю: память не растёт с размером входа, а при ошибке чтения
+// или сбое процесса уже дописанные пачки остаются в истории.
+const importBatch = 256
+
+func runImport(a *app, args []string) error {
+	fs := a.flagSet("import", "import -kind steps|trainings|gpx|tcx [-input файл] [-type тип] [-mode strict|lenient] [-implausible flag|reject|off] [-dry-run]",
+		"Разбирает записи (по одной в строке) с учётом сохранённого профиля и дописывает их в историю.\n"+
+			"Записи с ошибками выводятся в поток ошибок и пропускаются. Неправдоподобные записи\n"+
+			"отклоняются или принимаются с предупреждением, которое выводится в поток ошибок.\n"+
+			"Файлы GPX и TCX разбираются целиком: каждый трек или активность становится\n"+
+			"тренировкой с дистанцией, измеренной устройством или по координатам. Калории\n"+
+			"из TCX попадают в отчёт вместе с расчётной оценкой.")
+	kind := fs.String("kind", "", "вид записей: steps — дневная активность, trainings — тренировки, gpx — треки GPX, tcx — активности Garmin TCX")
+	input := fs.String("input", "-", "файл с записями; - — стандартный ввод")
+	activity := fs.String("type", "", "тип тренировки для треков; по умолчанию — тип из файла")
+	mode := modeFlag(fs)
+	implausible := implausibleFlag(fs)
+	dryRun := fs.Bool("dry-run", false, "только вывести результаты в выбранном формате, не сохраняя их")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	opts, err := parseOptions(*mode)
+	if err != nil {
+		return err
+	}
+	rules, err := a.plausibilityRules(*implausible)
+	if err != nil {
+		return err
+	}
+	if *activity != "" {
+		if _, ok := trainings.DefaultRegistry().LookupFold(*activity); !ok {
+			return usageError{err: i18n.Errorf("неизвестный тип тренировки: %s", *activity)}
+		}
+	}
+
+	person, err := a.loadProfile()
+	if err != nil {
+		return err
+	}
+	var parser recordParser
+	if !isTrackKind(*kind) {
+		if parser, err = newRecordParser(*kind, person, opts, rules); err != nil {
+			return err
+		}
+	}
+
+	r, closeInput, err := a.openInput(*input)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	// В режиме -dry-run записи только выводятся; иначе они дописываются
+	// в историю пачками по мере чтения.
+	var enc report.Encoder
+	var store *history.Store
+	if *dryRun {
+		if enc, err = report.NewEncoder(a.stdout, a.format); err != nil {
+			return err
+		}
+	} else if store, err = history.Open(a.historyPath()); err != nil {
+		return err
+	}
+
+	entries := make([]history.Entry, 0, importBatch)
+	flush := func() error {
+		if err := store.Append(entries...); err != nil {
+			return err
+		}
+		entries = entries[:0]
+		return nil
+	}
+
+	var flagged int
+	handle := func(res actioninfo.Result, e history.Entry) error {
+		if !res.OK() {
+			fmt.Fprintln(a.stderr, i18n.T("Ошибка при обработке данных '%s': %v", res.Input, res.Err))
+			return nil
+		}
+		if a.warn(res.Input, e.Result) {
+			flagged++
+		}
+		if enc != nil {
+			return enc.Encode(e.Result)
+		}
+		entries = append(entries, e)
+		if len(entries) == importBatch {
+			return flush()
+		}
+		return nil
+	}
+
+	var summary actioninfo.Summary
+	if parser == nil {
+		tracks, err := readTracks(r, *kind, *input, *activity)
+		if err != nil {
+			return err
+		}
+		summary, err = processTracks(tracks, person, rules, handle)
+	} else {
+		summary, err = processRecords(r, parser, handle)
+	}
+	if store != nil {
+		// Записи, прочитанные до ошибки, тоже сохраняются.
+		if ferr := flush(); err == nil {
+			err = ferr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if enc != nil {
+		if err := enc.Close(); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprint(a.stderr, i18n.T("Импортировано записей: %d, с ошибками: %d\n", summary.Succeeded, summary.Failed))
+		if flagged > 0 {
+			fmt.Fprint(a.stderr, i18n.T("Записей с предупреждениями: %d\n", flagged))
+		}
+	}
+
+	if summary.Failed > 0 {
+		return partialError{failed: summary.Failed, total: summary.Total}
+	}
+	return nil
+}
+
+// processRecords разбирает записи по одной в строке и передаёт их в handle.
+func processRecords(r io.Reader, parser recordParser, handle entryHandler) (actioninfo.Summary, error) {
+	return actioninfo.ProcessStream(r, parser, func(res actioninfo.Result) error {
+		if !res.OK() {
+			return handle(res, history.Entry{})
+		}
+		return handle(res, parser.entry())
+	})
+}
+
+// openInput открывает файл с записями; "-" означает стандартный ввод.
+func (a *app) openInput(path string) (io.Reader, func(), error) {
+	if path == "-" {
+		return a.stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { f.Close() }, nil
+}
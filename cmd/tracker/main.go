@@ -1,60 +1,269 @@
+// Команда tracker обрабатывает записи о дневной активности и тренировках,
+// хранит их историю и строит по ней отчёты.
+//
+// Использование:
+//
+//	tracker [флаги] <команда> [флаги команды]
+//
+// Список команд выводит tracker -help, описание флагов команды —
+// tracker <команда> -help.
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 
-	"FINAL-PROJECT-5/internal/actioninfo"
-	"FINAL-PROJECT-5/internal/daysteps"
-	"FINAL-PROJECT-5/internal/personaldata"
-	"FINAL-PROJECT-5/internal/trainings"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/units"
+)
+
+// Коды завершения.
+const (
+	exitOK      = 0 // команда выполнена.
+	exitError   = 1 // ошибка выполнения.
+	exitUsage   = 2 // неверные аргументы командной строки.
+	exitPartial = 3 // часть записей не обработана.
+)
+
+// Переменные окружения с каталогом данных и именем профиля.
+const (
+	dirEnvVar     = "TRACKER_DIR"
+	profileEnvVar = "TRACKER_PROFILE"
 )
 
 func main() {
-	person := personaldata.Personal{
-		Name:   "Витя",
-		Weight: 84.6,
-		Height: 1.87,
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// command — подкоманда tracker.
+type command struct {
+	name    string
+	summary string
+	run     func(a *app, args []string) error
+}
+
+var commands = []command{
+	{"import", "разобрать записи и сохранить их в историю", runImport},
+	{"report", "итоги за дни, недели или месяцы", runReport},
+	{"export", "выгрузить записи из истории", runExport},
+	{"profile", "показать, изменить и выбрать профиль: show, set, list, use", runProfile},
+	{"serve", "запустить HTTP API", runServe},
+}
+
+// app — состояние одного запуска: потоки ввода-вывода и общие флаги.
+type app struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+	opts           options
+	format         report.Format
+}
+
+// options — общие флаги. Они принимаются и до, и после имени команды.
+type options struct {
+	format  string
+	lang    string
+	units   string
+	dir     string
+	profile string
+}
+
+// register добавляет общие флаги в fs; значения по умолчанию берутся
+// из уже разобранных флагов.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "format", o.format, "формат вывода: text, json, ndjson или csv")
+	fs.StringVar(&o.lang, "lang", o.lang, "язык сообщений: ru или en (также переменная окружения "+i18n.EnvVar+")")
+	fs.StringVar(&o.units, "units", o.units, "система единиц: metric или imperial")
+	fs.StringVar(&o.dir, "dir", o.dir, "каталог с настройками и историей (также переменная окружения "+dirEnvVar+")")
+	fs.StringVar(&o.profile, "profile", o.profile, "имя профиля; по умолчанию — профиль по умолчанию из настроек (также переменная окружения "+profileEnvVar+")")
+}
+
+// apply проверяет общие флаги и применяет язык и систему единиц.
+func (a *app) apply() error {
+	locale, err := i18n.ParseLocale(a.opts.lang)
+	if err != nil {
+		return usageError{err: err}
+	}
+	i18n.SetLocale(locale)
+
+	system, err := units.ParseSystem(a.opts.units)
+	if err != nil {
+		return usageError{err: err}
+	}
+	units.SetSystem(system)
+
+	if a.format, err = report.ParseFormat(a.opts.format); err != nil {
+		return usageError{err: err}
+	}
+	return nil
+}
+
+func (a *app) configPath() string {
+	return filepath.Join(a.opts.dir, "config.json")
+}
+
+func (a *app) historyPath() string {
+	return filepath.Join(a.opts.dir, "history.jsonl")
+}
+
+// flagSet создаёт набор флагов команды с общими флагами и справкой.
+func (a *app) flagSet(name, usage, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: tracker %s\n\n%s\n\nФлаги:\n", usage, description)
+		fs.PrintDefaults()
 	}
+	a.opts.register(fs)
+	return fs
+}
 
-	// дневная активность
-	input := []string{
-		"678,0h50m",
-		"792,1h14m",
-		"1078,1h30m",
-		"7830,2h40m",
-		",3456",
-		"12:40:00, 3456",
-		"something is wrong",
+// parse разбирает флаги команды и применяет общие флаги.
+func (a *app) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		// flag уже вывел ошибку вместе со справкой.
+		return usageError{err: err, reported: true}
 	}
+	return a.apply()
+}
+
+// usageError — ошибка в аргументах командной строки. reported означает,
+// что сообщение об ошибке уже выведено.
+type usageError struct {
+	err      error
+	reported bool
+}
+
+func (e usageError) Error() string { return e.err.Error() }
+func (e usageError) Unwrap() error { return e.err }
+
+// partialError сообщает, что часть записей обработать не удалось.
+type partialError struct {
+	failed, total int
+}
 
-	fmt.Println("Активность в течение дня")
+func (e partialError) Error() string {
+	return i18n.T("не обработано записей: %d из %d", e.failed, e.total)
+}
 
-	daySteps := daysteps.DaySteps{
-		Personal: person,
+// run выполняет команду и возвращает код завершения.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	a := &app{
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+		opts: options{
+			format:  string(report.FormatText),
+			lang:    string(i18n.FromEnv()),
+			units:   string(units.Metric),
+			dir:     defaultDir(),
+			profile: os.Getenv(profileEnvVar),
+		},
 	}
 
-	daySteps.Print()
+	fs := flag.NewFlagSet("tracker", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { printUsage(fs) }
+	a.opts.register(fs)
 
-	actioninfo.Info(input, &daySteps)
+	err := a.parse(fs, args)
+	if err == nil {
+		err = a.dispatch(fs.Args())
+	}
+	return exitCode(stderr, err)
+}
 
-	// // тренировки
-	actions := []string{
-		"3456,Ходьба,3h00m",
-		"something is wrong",
-		"678,Бег,0h5m",
-		"1078,Бег,0h10m",
-		",3456 Ходьба",
-		"7892,Ходьба,3h10m",
-		"15392,Бег,0h45m",
+func (a *app) dispatch(args []string) error {
+	if len(args) == 0 {
+		printUsageTo(a.stderr)
+		return usageError{err: i18n.Errorf("не задана команда")}
 	}
 
-	trains := trainings.Training{
-		Personal: person,
+	name := args[0]
+	if name == "help" {
+		return a.help(args[1:])
+	}
+	for _, c := range commands {
+		if c.name == name {
+			return c.run(a, args[1:])
+		}
 	}
+	printUsageTo(a.stderr)
+	return usageError{err: i18n.Errorf("неизвестная команда: %s", name)}
+}
 
-	fmt.Println("Журнал тренировок")
+// help выводит общую справку или справку по команде.
+func (a *app) help(args []string) error {
+	if len(args) == 0 {
+		printUsageTo(a.stdout)
+		return nil
+	}
+	for _, c := range commands {
+		if c.name == args[0] {
+			a.stderr = a.stdout
+			return c.run(a, []string{"-help"})
+		}
+	}
+	return usageError{err: i18n.Errorf("неизвестная команда: %s", args[0])}
+}
 
-	trains.Print()
+func printUsage(fs *flag.FlagSet) {
+	printUsageTo(fs.Output())
+	fmt.Fprintln(fs.Output(), "\nОбщие флаги (принимаются и после имени команды):")
+	fs.PrintDefaults()
+}
 
-	actioninfo.Info(actions, &trains)
+func printUsageTo(w io.Writer) {
+	fmt.Fprintln(w, "Использование: tracker [флаги] <команда> [флаги команды]")
+	fmt.Fprintln(w, "\nКоманды:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "  help     справка по команде: tracker help <команда>")
+	fmt.Fprintf(w, "\nКоды завершения: %d — успех, %d — ошибка, %d — неверные аргументы, %d — часть записей не обработана.\n",
+		exitOK, exitError, exitUsage, exitPartial)
+}
+
+// exitCode выводит ошибку и возвращает соответствующий ей код завершения.
+func exitCode(stderr io.Writer, err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+
+	var partial partialError
+	var usage usageError
+	switch {
+	case errors.As(err, &partial):
+		fmt.Fprintln(stderr, err)
+		return exitPartial
+	case errors.As(err, &usage):
+		if !usage.reported {
+			fmt.Fprintln(stderr, err)
+		}
+		return exitUsage
+	}
+	fmt.Fprintln(stderr, err)
+	return exitError
+}
+
+// defaultDir возвращает каталог данных по умолчанию.
+func defaultDir() string {
+	if dir := os.Getenv(dirEnvVar); dir != "" {
+		return dir
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(dir, "tracker")
 }
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/server"
+	"FINAL-PROJECT-5/internal/trainings"
+	"FINAL-PROJECT-5/internal/units"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tracker запускает команду с каталогом данных dir.
+func tracker(t *testing.T, dir, stdin string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	t.Setenv(profileEnvVar, "")
+	t.Cleanup(func() {
+		i18n.SetLocale(i18n.DefaultLocale)
+		units.SetSystem(units.Metric)
+	})
+
+	var out, errOut bytes.Buffer
+	args = append([]string{"-dir", dir, "-lang", "ru"}, args...)
+	code = run(args, strings.NewReader(stdin), &out, &errOut)
+	return code, out.String(), errOut.String()
+}
+
+func TestUsage(t *testing.T) {
+	dir := t.TempDir()
+
+	code, _, stderr := tracker(t, dir, "")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "Команды:")
+
+	code, _, stderr = tracker(t, dir, "", "bogus")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, "неизвестная команда: bogus")
+
+	code, _, _ = tracker(t, dir, "", "-help")
+	assert.Equal(t, exitOK, code)
+
+	code, stdout, _ := tracker(t, dir, "", "help", "import")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "Использование: tracker import")
+
+	code, _, _ = tracker(t, dir, "", "report", "-period", "year")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = tracker(t, dir, "", "report", "-nope")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = tracker(t, dir, "", "-format", "xml", "export")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestProfile(t *testing.T) {
+	dir := t.TempDir()
+
+	code, _, stderr := tracker(t, dir, "", "profile", "show")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "файл настроек не найден")
+
+	code, _, stderr = tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "187cm", "-sex", "m")
+	require.Equal(t, exitOK, code, stderr)
+
+	code, _, _ = tracker(t, dir, "", "-units", "imperial", "profile", "set", "-weight", "180")
+	require.Equal(t, exitOK, code)
+
+	code, stdout, _ := tracker(t, dir, "", "profile", "show")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "Имя: Витя\nВес: 81.65 кг.\nРост: 1.87 м.\nПол: мужской\n\n", stdout)
+
+	code, stdout, _ = tracker(t, dir, "", "profile", "show", "-format", "json")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"name": "Витя"`)
+
+	code, _, _ = tracker(t, dir, "", "profile", "set", "-weight", "abc")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = tracker(t, dir, "", "profile", "set", "-weight", "NaN")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = tracker(t, dir, "", "profile")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestNamedProfiles(t *testing.T) {
+	dir := t.TempDir()
+
+	code, _, _ := tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+	code, _, _ = tracker(t, dir, "", "-profile", "anna", "profile", "set", "-name", "Анна", "-weight", "60", "-height", "1.65")
+	require.Equal(t, exitOK, code)
+
+	code, stdout, _ := tracker(t, dir, "", "profile", "list")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "  anna\n* default\n", stdout)
+
+	code, stdout, _ = tracker(t, dir, "", "profile", "show", "-profile", "anna")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "Имя: Анна")
+
+	t.Setenv(profileEnvVar, "anna")
+	var out bytes.Buffer
+	assert.Equal(t, exitOK, run([]string{"-dir", dir, "profile", "show"}, strings.NewReader(""), &out, &bytes.Buffer{}))
+	assert.Contains(t, out.String(), "Имя: Анна", "профиль выбирается переменной окружения")
+
+	code, _, _ = tracker(t, dir, "", "profile", "use", "anna")
+	require.Equal(t, exitOK, code)
+	code, stdout, _ = tracker(t, dir, "", "profile", "show")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "Имя: Анна")
+
+	code, _, stderr := tracker(t, dir, "", "profile", "use", "petya")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "профиль не найден: petya")
+	code, _, stderr = tracker(t, dir, "", "profile", "show", "-profile", "petya")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "профиль не найден: petya")
+
+	code, _, stderr = tracker(t, dir, "", "-profile", "anna", "profile", "set", "-max-hr", "300")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "profiles.anna.max_heart_rate")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"),
+		[]byte(`{"profiles": {"anna": {"name": "Анна", "weight_kg": "60", "height_m": 1.65}}}`), 0o644))
+	code, _, stderr = tracker(t, dir, "", "profile", "show")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "profiles.anna.weight_kg")
+}
+
+func TestImportReportExport(t *testing.T) {
+	dir := t.TempDir()
+
+	steps := "2024-04-29T08:00:00+03:00,6000,1h\n2024-05-01T08:00:00+03:00,8000,1h30m\n"
+	trainingsPath := filepath.Join(dir, "trainings.txt")
+	require.NoError(t, os.WriteFile(trainingsPath, []byte(
+		"2024-05-01T19:00:00+03:00,5000,Running,30m\nsomething is wrong\n2024-05-07T19:00:00+03:00,3456,Ходьба,1h\n"), 0o644))
+
+	code, _, _ := tracker(t, dir, steps, "import", "-kind", "steps")
+	assert.Equal(t, exitError, code, "без профиля импорт невозможен")
+
+	code, _, _ = tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+
+	code, _, _ = tracker(t, dir, steps, "import")
+	assert.Equal(t, exitUsage, code, "вид записей обязателен")
+
+	code, stdout, _ := tracker(t, dir, steps, "import", "-kind", "steps", "-dry-run", "-format", "csv")
+	assert.Equal(t, exitOK, code)
+	assert.Len(t, strings.Split(strings.TrimSpace(stdout), "\n"), 3)
+
+	code, _, stderr := tracker(t, dir, steps, "import", "-kind", "steps")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stderr, "Импортировано записей: 2, с ошибками: 0")
+
+	code, _, stderr = tracker(t, dir, "", "import", "-kind", "trainings", "-input", trainingsPath)
+	assert.Equal(t, exitPartial, code)
+	assert.Contains(t, stderr, "Импортировано записей: 2, с ошибками: 1")
+	assert.Contains(t, stderr, "не обработано записей: 1 из 3")
+
+	code, stdout, _ = tracker(t, dir, "", "report", "-period", "month", "-format", "csv")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "month,2024-04-01,2024-05-01,total,,1,6000,")
+	assert.Contains(t, stdout, "month,2024-05-01,2024-06-01,total,,3,16456,")
+
+	code, stdout, _ = tracker(t, dir, "", "report", "-period", "week", "-type", "Бег", "-format", "csv")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, 2, strings.Count(stdout, "\n")-1, "одна неделя: строка итогов и строка бега")
+
+	code, stdout, _ = tracker(t, dir, "", "export", "-from", "2024-05-01", "-to", "2024-05-01", "-format", "ndjson")
+	assert.Equal(t, exitOK, code)
+	assert.Len(t, strings.Split(strings.TrimSpace(stdout), "\n"), 2)
+
+	code, stdout, _ = tracker(t, dir, "", "export", "-kind", "trainings", "-format", "csv")
+	assert.Equal(t, exitOK, code)
+	assert.Len(t, strings.Split(strings.TrimSpace(stdout), "\n"), 3)
+
+	code, _, _ = tracker(t, dir, "", "export", "-from", "вчера")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestOutputIndependentOfLocale(t *testing.T) {
+	dir := t.TempDir()
+	code, _, _ := tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+
+	code, _, stderr := tracker(t, dir, "2024-05-01T19:00:00+03:00,5000,Running,30m\n", "-lang", "en", "import", "-kind", "trainings")
+	require.Equal(t, exitOK, code, stderr)
+	code, _, stderr = tracker(t, dir, "2024-05-02T19:00:00+03:00,5000,Бег,30m\n", "import", "-kind", "trainings")
+	require.Equal(t, exitOK, code, stderr)
+
+	code, stdout, _ := tracker(t, dir, "", "-lang", "en", "export", "-format", "csv")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, 2, strings.Count(stdout, "\ntraining,Бег,"), "в CSV — каноническое название типа")
+
+	code, stdout, _ = tracker(t, dir, "", "-lang", "en", "export")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, 2, strings.Count(stdout, "Training type: Running\n"), "текстовый отчёт переводится")
+
+	code, stdout, _ = tracker(t, dir, "", "-lang", "en", "report", "-period", "week")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "  Running: 2 records,", "записи на разных языках — один тип")
+	assert.NotContains(t, stdout, "Бег")
+}
+
+// failingReader отдаёт данные, а затем вместо конца файла — ошибку.
+type failingReader struct {
+	r io.Reader
+}
+
+func (f failingReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("обрыв соединения")
+	}
+	return n, err
+}
+
+func TestImportBatches(t *testing.T) {
+	dir := t.TempDir()
+	code, _, _ := tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+
+	n := importBatch + 10
+	input := strings.Repeat("2024-05-01T08:00:00+03:00,6000,1h\n", n)
+
+	var stdout, stderr bytes.Buffer
+	code = run([]string{"-dir", dir, "-lang", "ru", "import", "-kind", "steps"},
+		failingReader{strings.NewReader(input)}, &stdout, &stderr)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr.String(), "обрыв соединения")
+
+	code, out, _ := tracker(t, dir, "", "export", "-format", "ndjson")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, n, strings.Count(out, "\n"), "записи, прочитанные до ошибки, сохраняются")
+}
+
+func TestRecordParserEntry(t *testing.T) {
+	person := personaldata.Personal{Weight: 84.6, Height: 1.87}
+	parser, err := newRecordParser(kindTrainings, person, record.ParseOptions{}, nil)
+	require.NoError(t, err)
+
+	input := "2024-05-01T08:00:00+03:00,6000,Бег,1h"
+	require.NoError(t, parser.Parse(input))
+	info, err := parser.ActionInfo()
+	require.NoError(t, err)
+
+	e := parser.entry()
+	assert.Equal(t, input, e.Input)
+	assert.Equal(t, trainings.Running, e.Activity)
+	assert.Equal(t, report.Text(e.Result), info, "текст и запись истории — из одного расчёта")
+}
+
+func TestImportMode(t *testing.T) {
+	dir := t.TempDir()
+	code, _, _ := tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+
+	input := "6 000,бег,1h,\n 3456 , Ходьба , 1h \n"
+
+	code, _, stderr := tracker(t, dir, input, "import", "-kind", "trainings", "-dry-run")
+	assert.Equal(t, exitPartial, code, "по умолчанию пробелы по краям полей отбрасываются, как прежде")
+	assert.Contains(t, stderr, "не обработано записей: 1 из 2")
+
+	code, _, stderr = tracker(t, dir, input, "import", "-kind", "trainings", "-dry-run", "-mode", "strict")
+	assert.Equal(t, exitPartial, code)
+	assert.Contains(t, stderr, "не обработано записей: 2 из 2")
+
+	code, stdout, stderr := tracker(t, dir, input, "import", "-kind", "trainings", "-dry-run", "-mode", "lenient", "-format", "csv")
+	require.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, ",Бег,6000,")
+	assert.Contains(t, stdout, ",Ходьба,3456,")
+
+	code, _, _ = tracker(t, dir, input, "import", "-kind", "trainings", "-mode", "loose")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestImportImplausible(t *testing.T) {
+	dir := t.TempDir()
+	code, _, _ := tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+
+	input := "6000,1h\n30000,1h\n14000,1h\n"
+
+	code, _, stderr := tracker(t, dir, input, "import", "-kind", "steps")
+	assert.Equal(t, exitPartial, code, "слишком высокий темп по умолчанию отклоняет запись")
+	assert.Contains(t, stderr, "Ошибка при обработке данных '30000,1h': неправдоподобный темп: 500 шагов/мин")
+	assert.Contains(t, stderr, "Предупреждение для записи '14000,1h': неправдоподобная скорость")
+	assert.Contains(t, stderr, "Импортировано записей: 2, с ошибками: 1\nЗаписей с предупреждениями: 1\n")
+
+	code, stdout, stderr := tracker(t, dir, input, "import", "-kind", "steps", "-dry-run", "-implausible", "flag", "-format", "ndjson")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, 2, strings.Count(stdout, `"warnings"`))
+	assert.Equal(t, 3, strings.Count(stderr, "Предупреждение для записи"))
+
+	code, _, stderr = tracker(t, dir, input, "import", "-kind", "steps", "-dry-run", "-implausible", "off")
+	assert.Equal(t, exitOK, code)
+	assert.NotContains(t, stderr, "Предупреждение")
+
+	code, _, _ = tracker(t, dir, input, "import", "-kind", "steps", "-implausible", "warn")
+	assert.Equal(t, exitUsage, code)
+
+	// Правила из файла настроек дополняют правила по умолчанию.
+	configPath := filepath.Join(dir, "config.json")
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	data = bytes.Replace(data, []byte(`"profiles"`), []byte(`"plausibility": {"actions": {"cadence": "flag"}}, "profiles"`), 1)
+	require.NoError(t, os.WriteFile(configPath, data, 0o644))
+
+	code, _, stderr = tracker(t, dir, input, "import", "-kind", "steps", "-dry-run")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "Предупреждение для записи '30000,1h': неправдоподобный темп")
+}
+
+// trackGPX — трек бега: 0.3 км на север за 90 с с минутной остановкой
+// и трек неизвестного типа.
+const trackGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><type>running</type><trkseg>
+    <trkpt lat="55.000" lon="37.000"><ele>100</ele><time>2024-05-01T07:00:00+03:00</time></trkpt>
+    <trkpt lat="55.001" lon="37.000"><ele>104</ele><time>2024-05-01T07:00:30+03:00</time></trkpt>
+    <trkpt lat="55.001" lon="37.000"><ele>104</ele><time>2024-05-01T07:01:30+03:00</time></trkpt>
+    <trkpt lat="55.003" lon="37.000"><ele>98</ele><time>2024-05-01T07:02:30+03:00</time></trkpt>
+  </trkseg></trk>
+  <trk><type>cycling</type><trkseg>
+    <trkpt lat="55.000" lon="37.000"><time>2024-05-02T07:00:00+03:00</time></trkpt>
+    <trkpt lat="55.010" lon="37.000"><time>2024-05-02T07:02:00+03:00</time></trkpt>
+  </trkseg></trk>
+</gpx>`
+
+func TestImportGPX(t *testing.T) {
+	dir := t.TempDir()
+	code, _, _ := tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+
+	path := filepath.Join(dir, "run.gpx")
+	require.NoError(t, os.WriteFile(path, []byte(trackGPX), 0o644))
+
+	code, stdout, stderr := tracker(t, dir, "", "import", "-kind", "gpx", "-input", path, "-dry-run", "-format", "ndjson")
+	assert.Equal(t, exitPartial, code)
+	assert.Contains(t, stderr, "Ошибка при обработке данных '"+path+"#2': неизвестный тип тренировки: cycling")
+	assert.Contains(t, stdout, `"type":"Бег","steps":0,"duration":"1m30s"`)
+	assert.Contains(t, stdout, `"distance_km":0.333`)
+	assert.Contains(t, stdout, `"elapsed":"2m30s"`, "остановка на одном месте — это промежуток без движения")
+	assert.Contains(t, stdout, `"elevation_gain_m":4,"elevation_loss_m":6`)
+
+	code, _, stderr = tracker(t, dir, trackGPX, "import", "-kind", "gpx", "-type", "ходьба")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "Импортировано записей: 2, с ошибками: 0\nЗаписей с предупреждениями: 2\n",
+		"для ходьбы скорость трека неправдоподобна")
+
+	code, stdout, _ = tracker(t, dir, "", "export", "-type", "Ходьба", "-format", "ndjson")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, 2, strings.Count(stdout, "\n"))
+
+	code, _, _ = tracker(t, dir, trackGPX, "import", "-kind", "gpx", "-type", "Плавание")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = tracker(t, dir, "<gpx>", "import", "-kind", "gpx")
+	assert.Equal(t, exitError, code)
+}
+
+// activityTCX — пробежка на 2 км за 10 минут с калориями и каденсом
+// устройства и велотренировка.
+const activityTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+  <Activities>
+    <Activity Sport="Running">
+      <Id>2024-05-01T07:00:00+03:00</Id>
+      <Lap StartTime="2024-05-01T07:00:00+03:00">
+        <TotalTimeSeconds>600</TotalTimeSeconds>
+        <DistanceMeters>2000</DistanceMeters>
+        <Calories>150</Calories>
+        <Extensions><ns3:LX><ns3:AvgRunCadence>80</ns3:AvgRunCadence></ns3:LX></Extensions>
+      </Lap>
+    </Activity>
+    <Activity Sport="Biking">
+      <Id>2024-05-02T07:00:00+03:00</Id>
+      <Lap StartTime="2024-05-02T07:00:00+03:00"><TotalTimeSeconds>600</TotalTimeSeconds></Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestImportTCX(t *testing.T) {
+	dir := t.TempDir()
+	code, _, _ := tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+
+	code, stdout, stderr := tracker(t, dir, activityTCX, "import", "-kind", "tcx", "-dry-run", "-format", "ndjson")
+	assert.Equal(t, exitPartial, code)
+	assert.Contains(t, stderr, "Ошибка при обработке данных '-#2': для вида спорта Biking нет типа тренировки: укажите тип явно (-type)")
+	assert.Contains(t, stdout, `"type":"Бег","steps":1600,"duration":"10m0s"`)
+	assert.Contains(t, stdout, `"distance_km":2,"speed_kmh":12,"calories_kcal":150,"estimated_calories_kcal":169.2`)
+
+	code, stdout, stderr = tracker(t, dir, activityTCX, "import", "-kind", "tcx", "-type", "ходьба", "-dry-run")
+	assert.Equal(t, exitPartial, code)
+	assert.Contains(t, stdout, "Сожгли калорий: 150.00\nКалории — по данным устройства; по расчёту:")
+	assert.Contains(t, stderr, "Ошибка при обработке данных '-#2': ошибка при расчете калорий",
+		"без шагов, дистанции и калорий устройства тренировку не рассчитать")
+
+	code, _, _ = tracker(t, dir, "<TrainingCenterDatabase>", "import", "-kind", "tcx")
+	assert.Equal(t, exitError, code)
+}
+
+func TestProfileMeasure(t *testing.T) {
+	dir := t.TempDir()
+
+	code, _, _ := tracker(t, dir, "", "profile", "measure", "-weight", "90")
+	assert.Equal(t, exitError, code, "без профиля замер добавить некуда")
+
+	code, _, _ = tracker(t, dir, "", "profile", "set", "-name", "Витя", "-weight", "84.6", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+	code, _, stderr := tracker(t, dir, "", "profile", "measure", "-time", "2024-01-01", "-weight", "90")
+	require.Equal(t, exitOK, code, stderr)
+	code, _, _ = tracker(t, dir, "", "profile", "measure", "-time", "2024-01-11T00:00:00Z", "-weight", "80", "-body-fat", "18")
+	require.Equal(t, exitOK, code)
+
+	code, _, _ = tracker(t, dir, "", "profile", "measure", "-time", "2024-01-12")
+	assert.Equal(t, exitError, code, "замер без параметров недопустим")
+	code, _, _ = tracker(t, dir, "", "profile", "measure", "-weight", "много")
+	assert.Equal(t, exitUsage, code)
+
+	code, stdout, _ := tracker(t, dir, "", "profile", "show", "-format", "json")
+	require.Equal(t, exitOK, code)
+	assert.Equal(t, 2, strings.Count(stdout, `"time"`))
+
+	code, stdout, _ = tracker(t, dir, "2024-01-06T00:00:00Z,6000,1h\n", "import", "-kind", "steps", "-dry-run", "-format", "json")
+	require.Equal(t, exitOK, code)
+	withLog := stdout
+
+	// Без журнала замеров, но с весом 85 кг — интерполированным между 90 и 80.
+	plain := t.TempDir()
+	code, _, _ = tracker(t, plain, "", "profile", "set", "-name", "Витя", "-weight", "85", "-height", "1.87")
+	require.Equal(t, exitOK, code)
+	code, stdout, _ = tracker(t, plain, "2024-01-06T00:00:00Z,6000,1h\n", "import", "-kind", "steps", "-dry-run", "-format", "json")
+	require.Equal(t, exitOK, code)
+	assert.JSONEq(t, stdout, withLog, "вес на момент записи берётся из журнала замеров")
+}
+
+func TestServe(t *testing.T) {
+	dir := t.TempDir()
+
+	code, _, _ := tracker(t, dir, "", "serve", "-addr", "bogus")
+	assert.Equal(t, exitError, code)
+	code, _, stderr := tracker(t, dir, "", "-profile", "nobody", "serve")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "файл настроек не найден")
+
+	a := &app{opts: options{dir: dir}}
+	opts, err := a.serverOptions()
+	require.NoError(t, err)
+	assert.Nil(t, opts.Profile, "без настроек профиль передаётся в запросах")
+	assert.NotNil(t, opts.Store)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	var log bytes.Buffer
+	go func() { done <- serve(ctx, ln, server.New(opts), &log) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/openapi.json")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	assert.NoError(t, <-done)
+	assert.Contains(t, log.String(), ln.Addr().String())
+}
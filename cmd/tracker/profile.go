@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/profile"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/units"
+)
+
+// defaultProfileName — имя первого профиля, если оно не задано флагом -profile.
+const defaultProfileName = "default"
+
+const profileUsage = "profile show | profile set [флаги] | profile measure [флаги] | profile list | profile use <имя>"
+
+const profileDescription = "Профили хранятся в файле config.json в каталоге данных.\n" +
+	"  show     выводит профиль, выбранный флагом -profile, или профиль по умолчанию;\n" +
+	"  set      изменяет или создаёт профиль (флаги — tracker profile set -help);\n" +
+	"  measure  добавляет замер веса, роста или доли жира (флаги — tracker profile measure -help);\n" +
+	"  list     выводит имена профилей, профиль по умолчанию отмечен звёздочкой;\n" +
+	"  use      делает профиль профилем по умолчанию."
+
+func runProfile(a *app, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "show":
+			return runProfileShow(a, args[1:])
+		case "set":
+			return runProfileSet(a, args[1:])
+		case "measure":
+			return runProfileMeasure(a, args[1:])
+		case "list":
+			return runProfileList(a, args[1:])
+		case "use":
+			return runProfileUse(a, args[1:])
+		case "-help", "--help", "-h", "--h":
+			a.stderr = a.stdout
+			return a.parse(a.flagSet("profile", profileUsage, profileDescription), args)
+		}
+	}
+	return usageError{err: i18n.Errorf("ожидается подкоманда profile show, set, measure, list или use")}
+}
+
+func runProfileShow(a *app, args []string) error {
+	fs := a.flagSet("profile show", profileUsage, profileDescription)
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	person, err := a.loadProfile()
+	if err != nil {
+		return err
+	}
+
+	if a.format == report.FormatText {
+		person.Fprint(a.stdout)
+		return nil
+	}
+	return a.writeJSON(person)
+}
+
+func runProfileList(a *app, args []string) error {
+	fs := a.flagSet("profile list", profileUsage, profileDescription)
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	c, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if a.format != report.FormatText {
+		return a.writeJSON(c)
+	}
+	for _, name := range c.Names() {
+		mark := " "
+		if name == c.Default {
+			mark = "*"
+		}
+		fmt.Fprintf(a.stdout, "%s %s\n", mark, name)
+	}
+	return nil
+}
+
+func runProfileUse(a *app, args []string) error {
+	fs := a.flagSet("profile use", profileUsage, profileDescription)
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return usageError{err: i18n.Errorf("ожидается имя профиля")}
+	}
+
+	c, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	name, err := c.Resolve(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	c.Default = name
+	return profile.Save(a.configPath(), c)
+}
+
+func runProfileSet(a *app, args []string) error {
+	fs := a.flagSet("profile set", "profile set [-profile имя] [-name имя] [-weight вес] [-height рост] [-age лет] [-sex пол] [-resting-hr уд/мин] [-max-hr уд/мин] [-body-fat %] [-default]",
+		"Изменяет профиль, выбранный флагом -profile, или профиль по умолчанию; если профиля\n"+
+			"ещё нет, он создаётся. Незаданные флаги оставляют поля без изменений.\n"+
+			"Вес и рост принимаются с единицами (84.6kg, 186lb, 187cm, 6'2\"); число без единицы\n"+
+			"считается записанным в системе единиц из флага -units.")
+	name := fs.String("name", "", "имя")
+	weight := fs.String("weight", "", "вес")
+	height := fs.String("height", "", "рост")
+	age := fs.Int("age", 0, "возраст, полных лет")
+	sex := fs.String("sex", "", "пол: male или female")
+	restingHR := fs.Int("resting-hr", 0, "пульс в покое, уд/мин")
+	maxHR := fs.Int("max-hr", 0, "максимальный пульс, уд/мин")
+	bodyFat := fs.Float64("body-fat", 0, "доля жира, %")
+	makeDefault := fs.Bool("default", false, "сделать профиль профилем по умолчанию")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	c, err := a.loadConfig()
+	if err != nil && !errors.Is(err, profile.ErrNotFound) {
+		return err
+	}
+
+	key := a.opts.profile
+	if key == "" {
+		if key, err = c.Resolve(""); err != nil {
+			if !errors.Is(err, profile.ErrNotFound) {
+				return err
+			}
+			key = defaultProfileName
+		}
+	}
+	person := c.Profiles[key]
+
+	sys := units.Current()
+	var setErr error
+	fs.Visit(func(f *flag.Flag) {
+		if setErr != nil {
+			return
+		}
+		switch f.Name {
+		case "name":
+			person.Name = *name
+		case "weight":
+			person.Weight, setErr = units.ParseWeight(*weight, sys)
+		case "height":
+			person.Height, setErr = units.ParseHeight(*height, sys)
+		case "age":
+			person.Age = *age
+		case "sex":
+			person.Sex, setErr = personaldata.ParseSex(*sex)
+		case "resting-hr":
+			person.RestingHeartRate = *restingHR
+		case "max-hr":
+			person.MaxHeartRate = *maxHR
+		case "body-fat":
+			person.BodyFat = *bodyFat
+		}
+	})
+	if setErr != nil {
+		return usageError{err: setErr}
+	}
+
+	c.Set(key, person)
+	if *makeDefault {
+		c.Default = key
+	}
+	return profile.Save(a.configPath(), c)
+}
+
+func runProfileMeasure(a *app, args []string) error {
+	fs := a.flagSet("profile measure", "profile measure [-profile имя] [-time время] [-weight вес] [-height рост] [-body-fat %]",
+		"Добавляет замер в журнал профиля. По журналу калории и дистанция каждой записи\n"+
+			"считаются с параметрами тела на момент записи; между замерами значения\n"+
+			"интерполируются. Вес и рост принимаются так же, как в profile set.")
+	at := fs.String("time", "", "время замера: ГГГГ-ММ-ДД или RFC 3339; по умолчанию — текущее")
+	weight := fs.String("weight", "", "вес")
+	height := fs.String("height", "", "рост")
+	bodyFat := fs.Float64("body-fat", 0, "доля жира, %")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	c, err := a.loadConfig()
+	if err != nil {
+		return err
+	}
+	key, err := c.Resolve(a.opts.profile)
+	if err != nil {
+		return err
+	}
+
+	m := personaldata.Measurement{Time: time.Now(), BodyFat: *bodyFat}
+	if *at != "" {
+		if m.Time, _, err = parseDate(*at, time.Local); err != nil {
+			return usageError{err: err}
+		}
+	}
+	sys := units.Current()
+	if *weight != "" {
+		if m.Weight, err = units.ParseWeight(*weight, sys); err != nil {
+			return usageError{err: err}
+		}
+	}
+	if *height != "" {
+		if m.Height, err = units.ParseHeight(*height, sys); err != nil {
+			return usageError{err: err}
+		}
+	}
+
+	person := c.Profiles[key]
+	person.Measurements = append(person.Measurements, m)
+	c.Set(key, person)
+	return profile.Save(a.configPath(), c)
+}
+
+// loadConfig читает файл настроек.
+func (a *app) loadConfig() (profile.Config, error) {
+	return profile.Load(a.configPath())
+}
+
+// loadProfile возвращает профиль, выбранный флагом -profile,
+// или профиль по умолчанию.
+func (a *app) loadProfile() (personaldata.Personal, error) {
+	c, err := a.loadConfig()
+	if err == nil {
+		var person personaldata.Personal
+		if person, err = c.Profile(a.opts.profile); err == nil {
+			return person, nil
+		}
+	}
+	if errors.Is(err, profile.ErrNotFound) {
+		return personaldata.Personal{}, i18n.Errorf("%w; задайте профиль командой tracker profile set", err)
+	}
+	return personaldata.Personal{}, err
+}
+
+func (a *app) writeJSON(v any) error {
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
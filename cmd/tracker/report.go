@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"FINAL-PROJECT-5/internal/actioninfo"
+	"FINAL-PROJECT-5/internal/aggregate"
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/report"
+)
+
+func runReport(a *app, args []string) error {
+	fs := a.flagSet("report", "report [-period day|week|month] [-from дата] [-to дата] [-type тип] [-steps файл] [-trainings файл] [-mode strict|lenient] [-implausible flag|reject|off]",
+		"Выводит итоги за дни, недели или месяцы: шаги, дистанцию, активное время и калории,\n"+
+			"всего и по типам активности. По умолчанию итоги строятся по истории; с флагами\n"+
+			"-steps и -trainings — по записям из файлов без сохранения в историю.")
+	periodName := fs.String("period", string(aggregate.Week), "период: day, week или month")
+	tz := fs.String("tz", "", "часовой пояс для границ периодов, например Europe/Moscow; по умолчанию — пояс каждой записи")
+	var q queryFlags
+	q.register(fs)
+	stepsPath := fs.String("steps", "", "файл с записями дневной активности")
+	trainingsPath := fs.String("trainings", "", "файл с записями тренировок")
+	mode := modeFlag(fs)
+	implausible := implausibleFlag(fs)
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	period, err := aggregate.ParsePeriod(*periodName)
+	if err != nil {
+		return usageError{err: err}
+	}
+	opts, err := parseOptions(*mode)
+	if err != nil {
+		return err
+	}
+
+	var loc *time.Location
+	if *tz != "" {
+		if loc, err = time.LoadLocation(*tz); err != nil {
+			return usageError{err: i18n.Errorf("неизвестный часовой пояс: %s", *tz)}
+		}
+	}
+
+	query, err := q.query(loc)
+	if err != nil {
+		return err
+	}
+
+	agg := aggregate.New(period, loc)
+	add := func(e history.Entry) {
+		if !query.Match(e) {
+			return
+		}
+		if err := agg.Add(e.Result); err != nil {
+			fmt.Fprintln(a.stderr, i18n.T("Ошибка при обработке данных '%s': %v", e.Input, err))
+		}
+	}
+
+	if *stepsPath == "" && *trainingsPath == "" {
+		store, err := history.Open(a.historyPath())
+		if err != nil {
+			return err
+		}
+		entries, err := store.Query(query)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			add(e)
+		}
+		return aggregate.Write(a.stdout, a.format, agg.Buckets())
+	}
+
+	rules, err := a.plausibilityRules(*implausible)
+	if err != nil {
+		return err
+	}
+	person, err := a.loadProfile()
+	if err != nil {
+		return err
+	}
+	for kind, path := range map[string]string{kindSteps: *stepsPath, kindTrainings: *trainingsPath} {
+		if path == "" {
+			continue
+		}
+		if err := a.readEntries(kind, path, person, opts, rules, add); err != nil {
+			return err
+		}
+	}
+	return aggregate.Write(a.stdout, a.format, agg.Buckets())
+}
+
+// readEntries разбирает записи вида kind из файла path в режиме opts,
+// проверяет их правдоподобие по правилам rules и передаёт в fn. Записи
+// с ошибками выводятся в поток ошибок и пропускаются; предупреждения
+// тоже выводятся в поток ошибок.
+func (a *app) readEntries(kind, path string, person personaldata.Personal, opts record.ParseOptions, rules *plausibility.Rules, fn func(history.Entry)) error {
+	parser, err := newRecordParser(kind, person, opts, rules)
+	if err != nil {
+		return err
+	}
+	r, closeInput, err := a.openInput(path)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	_, err = actioninfo.ProcessStream(r, parser, func(res actioninfo.Result) error {
+		if !res.OK() {
+			fmt.Fprintln(a.stderr, i18n.T("Ошибка при обработке данных '%s': %v", res.Input, res.Err))
+			return nil
+		}
+		e := parser.entry()
+		a.warn(res.Input, e.Result)
+		fn(e)
+		return nil
+	})
+	return err
+}
+
+// queryFlags — флаги отбора записей истории.
+type queryFlags struct {
+	from, to string
+	kind     string
+	typ      string
+}
+
+func (q *queryFlags) register(fs interface {
+	StringVar(p *string, name, value, usage string)
+}) {
+	fs.StringVar(&q.from, "from", "", "начало интервала: ГГГГ-ММ-ДД или время в формате RFC 3339")
+	fs.StringVar(&q.to, "to", "", "конец интервала: ГГГГ-ММ-ДД (день включается) или время в формате RFC 3339 (не включается)")
+	fs.StringVar(&q.kind, "kind", "", "вид записей: steps или trainings; по умолчанию — все")
+	fs.StringVar(&q.typ, "type", "", "тип тренировки, например Бег")
+}
+
+// query строит условия отбора. Даты без времени отсчитываются
+// в часовом поясе loc или, если он не задан, в местном.
+func (q queryFlags) query(loc *time.Location) (history.Query, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var query history.Query
+	var err error
+	if q.from != "" {
+		if query.From, _, err = parseDate(q.from, loc); err != nil {
+			return history.Query{}, usageError{err: err}
+		}
+	}
+	if q.to != "" {
+		to, dateOnly, err := parseDate(q.to, loc)
+		if err != nil {
+			return history.Query{}, usageError{err: err}
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		query.To = to
+	}
+
+	switch q.kind {
+	case "":
+	case kindSteps:
+		query.Kind = report.KindDaySteps
+	case kindTrainings:
+		query.Kind = report.KindTraining
+	default:
+		return history.Query{}, usageError{err: i18n.Errorf("неизвестный вид записей: %q (ожидается %s или %s)", q.kind, kindSteps, kindTrainings)}
+	}
+
+	if q.typ != "" {
+		activity, ok := lookupActivity(q.typ)
+		if !ok {
+			return history.Query{}, usageError{err: i18n.Errorf("неизвестный тип тренировки: %s", q.typ)}
+		}
+		query.Activity = activity
+	}
+	return query, nil
+}
+
+// parseDate разбирает дату ГГГГ-ММ-ДД или время RFC 3339.
+func parseDate(s string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	if t, err := time.ParseInLocation(time.DateOnly, s, loc); err == nil {
+		return t, true, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	return time.Time{}, false, i18n.Errorf("неверный формат даты: %q", s)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/profile"
+	"FINAL-PROJECT-5/internal/server"
+)
+
+// shutdownTimeout — сколько ждать завершения текущих запросов при остановке.
+const shutdownTimeout = 10 * time.Second
+
+func runServe(a *app, args []string) error {
+	fs := a.flagSet("serve", "serve [-addr адрес]",
+		"Запускает HTTP API: POST /v1/steps, POST /v1/trainings, GET /v1/results,\n"+
+			"GET /v1/spentenergy. Описание API — GET /openapi.json. Профиль из флага -profile\n"+
+			"или профиль по умолчанию используется для запросов без поля profile; если\n"+
+			"профилей нет, профиль нужно передавать в каждом запросе. Сервер\n"+
+			"останавливается по SIGINT или SIGTERM, дожидаясь текущих запросов.")
+	addr := fs.String("addr", ":8080", "адрес, на котором принимать запросы")
+	if err := a.parse(fs, args); err != nil {
+		return err
+	}
+
+	opts, err := a.serverOptions()
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return serve(ctx, ln, server.New(opts), a.stderr)
+}
+
+// serverOptions загружает профиль и правила проверки правдоподобия
+// и открывает историю для сервера.
+// Отсутствие файла настроек не ошибка: тогда профиль передаётся в запросах.
+func (a *app) serverOptions() (server.Options, error) {
+	var opts server.Options
+
+	c, err := a.loadConfig()
+	if err == nil {
+		var person personaldata.Personal
+		rules := c.Rules()
+		opts.Rules = &rules
+		if person, err = c.Profile(a.opts.profile); err == nil {
+			opts.Profile = &person
+		}
+	}
+	if err != nil && !(errors.Is(err, profile.ErrNotFound) && a.opts.profile == "") {
+		return server.Options{}, err
+	}
+
+	if opts.Store, err = history.Open(a.historyPath()); err != nil {
+		return server.Options{}, err
+	}
+	return opts, nil
+}
+
+// serve обслуживает запросы на ln, пока не отменён ctx, а затем
+// останавливает сервер, дожидаясь текущих запросов.
+func serve(ctx context.Context, ln net.Listener, h http.Handler, log io.Writer) error {
+	srv := &http.Server{Handler: h, ReadHeaderTimeout: 10 * time.Second}
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.Serve(ln) }()
+	fmt.Fprint(log, i18n.T("Сервер принимает запросы на %s\n", ln.Addr()))
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"FINAL-PROJECT-5/internal/actioninfo"
+	"FINAL-PROJECT-5/internal/gpx"
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/tcx"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+// Виды файлов с треками: в отличие от записей, файл разбирается целиком.
+const (
+	kindGPX = "gpx"
+	kindTCX = "tcx"
+)
+
+// isTrackKind сообщает, что записи вида kind читаются из файла с треками.
+func isTrackKind(kind string) bool {
+	return kind == kindGPX || kind == kindTCX
+}
+
+// track — тренировка, прочитанная из файла с треками. Label — подпись
+// для сообщений об ошибках и поля input истории: путь к файлу, а если
+// треков в файле несколько — путь и номер трека.
+type track struct {
+	label    string
+	training trainings.Training
+	err      error
+}
+
+// trackSource — трек GPX или активность TCX.
+type trackSource interface {
+	Training(activity string) (trainings.Training, error)
+}
+
+// readTracks читает файл вида kind (GPX или TCX) и превращает каждый
+// трек или активность в тренировку типа activity (пусто — тип из файла).
+// Ошибка в отдельном треке не прерывает чтение остальных.
+func readTracks(r io.Reader, kind, path, activity string) ([]track, error) {
+	var sources []trackSource
+	switch kind {
+	case kindGPX:
+		parsed, err := gpx.Parse(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range parsed {
+			sources = append(sources, p)
+		}
+	case kindTCX:
+		parsed, err := tcx.Parse(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range parsed {
+			sources = append(sources, p)
+		}
+	}
+
+	tracks := make([]track, 0, len(sources))
+	for i, p := range sources {
+		t := track{label: path}
+		if len(sources) > 1 {
+			t.label = fmt.Sprintf("%s#%d", path, i+1)
+		}
+		t.training, t.err = p.Training(activity)
+		tracks = append(tracks, t)
+	}
+	return tracks, nil
+}
+
+// entryHandler получает результат обработки записи и, если ошибки
+// не было, запись истории.
+type entryHandler func(res actioninfo.Result, e history.Entry) error
+
+// processTracks рассчитывает показатели тренировок из файла с треками
+// и передаёт их в handle так же, как actioninfo.ProcessStream — записи.
+func processTracks(tracks []track, person personaldata.Personal, rules *plausibility.Rules, handle entryHandler) (actioninfo.Summary, error) {
+	var summary actioninfo.Summary
+	for i, t := range tracks {
+		res := actioninfo.Result{Index: i, Input: t.label, Err: t.err}
+
+		var e history.Entry
+		if res.Err != nil {
+			res.Stage = actioninfo.StageParse
+		} else {
+			t.training.Personal = person
+			t.training.Plausibility = rules
+			if e, res.Err = history.FromTraining(t.label, t.training); res.Err != nil {
+				res.Stage = actioninfo.StageInfo
+			}
+		}
+
+		summary.Add(res)
+		if err := handle(res, e); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
@@ -1,31 +1,164 @@
 package actioninfo
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"strings"
+
+	"FINAL-PROJECT-5/internal/i18n"
 )
 
+// maxLineSize — максимальная длина одной строки во входном потоке.
+const maxLineSize = 1024 * 1024
+
 // Парсинг
 type DataParser interface {
 	Parse(datastring string) error
 	ActionInfo() (string, error)
 }
 
+// Stage — этап обработки записи, на котором произошла ошибка.
+type Stage int
+
+const (
+	StageNone  Stage = iota // запись обработана успешно.
+	StageParse              // ошибка в Parse.
+	StageInfo               // ошибка в ActionInfo.
+)
+
+// Result — результат обработки одной записи.
+type Result struct {
+	Index int    // номер записи: позиция в наборе данных или номер строки в потоке (с нуля).
+	Input string // исходная строка.
+	Info  string // результат ActionInfo, если ошибки не было.
+	Err   error  // ошибка обработки.
+	Stage Stage  // этап, на котором произошла ошибка.
+}
+
+// OK сообщает, что запись обработана без ошибок.
+func (r Result) OK() bool {
+	return r.Err == nil
+}
+
+// Summary — итоги обработки набора записей.
+type Summary struct {
+	Total     int
+	Succeeded int
+	Failed    int
+}
+
+// Add учитывает результат r в итогах.
+func (s *Summary) Add(r Result) {
+	s.Total++
+	if r.OK() {
+		s.Succeeded++
+	} else {
+		s.Failed++
+	}
+}
+
+// Handler получает результат каждой записи. Ненулевая ошибка
+// прекращает обработку и возвращается вызывающему.
+type Handler func(Result) error
+
 func Info(dataset []string, dp DataParser) {
-	for _, entry := range dataset {
-		err := dp.Parse(entry)
-		if err != nil {
-			log.Printf("Ошибка при парсинге данных '%s': %v", entry, err)
-			continue
+	Process(dataset, dp, printResult(os.Stdout))
+}
+
+// InfoStream построчно читает записи из r, передаёт каждую парсеру dp
+// и пишет результаты в w. Пустые строки пропускаются, данные целиком
+// в память не загружаются.
+func InfoStream(r io.Reader, w io.Writer, dp DataParser) error {
+	_, err := ProcessStream(r, dp, printResult(w))
+	return err
+}
+
+// Process обрабатывает набор записей и передаёт результат каждой в handle.
+func Process(dataset []string, dp DataParser, handle Handler) (Summary, error) {
+	var summary Summary
+
+	for i, entry := range dataset {
+		res := processEntry(i, entry, dp)
+		summary.Add(res)
+
+		if err := handle(res); err != nil {
+			return summary, err
 		}
+	}
 
-		infoStr, err := dp.ActionInfo()
-		if err != nil {
-			log.Printf("Ошибка при получении информации об активности для '%s': %v", entry, err)
+	return summary, nil
+}
+
+// ProcessStream построчно читает записи из r и передаёт результат каждой
+// в handle. Пустые строки пропускаются, но учитываются в Result.Index.
+func ProcessStream(r io.Reader, dp DataParser, handle Handler) (Summary, error) {
+	var summary Summary
+
+	scanner := newScanner(r)
+	for line := 0; scanner.Scan(); line++ {
+		entry := strings.TrimSuffix(scanner.Text(), "\r")
+		if entry == "" {
 			continue
 		}
 
-		fmt.Println(infoStr)
+		res := processEntry(line, entry, dp)
+		summary.Add(res)
+
+		if err := handle(res); err != nil {
+			return summary, err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return summary, i18n.Errorf("ошибка чтения данных: %w", err)
+	}
+
+	return summary, nil
+}
+
+func newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	return scanner
+}
+
+func processEntry(index int, entry string, dp DataParser) Result {
+	res := Result{Index: index, Input: entry}
+
+	if err := dp.Parse(entry); err != nil {
+		res.Err, res.Stage = err, StageParse
+		return res
 	}
 
+	info, err := dp.ActionInfo()
+	if err != nil {
+		res.Err, res.Stage = err, StageInfo
+		return res
+	}
+
+	res.Info = info
+	return res
+}
+
+// printResult возвращает обработчик, который пишет успешные результаты в w,
+// а ошибки — в стандартный лог.
+func printResult(w io.Writer) Handler {
+	return func(res Result) error {
+		switch res.Stage {
+		case StageParse:
+			log.Print(i18n.T("Ошибка при парсинге данных '%s': %v", res.Input, res.Err))
+			return nil
+		case StageInfo:
+			log.Print(i18n.T("Ошибка при получении информации об активности для '%s': %v", res.Input, res.Err))
+			return nil
+		}
+
+		if _, err := fmt.Fprintln(w, res.Info); err != nil {
+			return i18n.Errorf("ошибка записи результата: %w", err)
+		}
+		return nil
+	}
 }
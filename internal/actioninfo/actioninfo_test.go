@@ -2,12 +2,15 @@ package actioninfo
 
 import (
 	"bytes"
+	"errors"
 	"log"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockDataParser is a mock implementation of the DataParser interface
@@ -83,3 +86,93 @@ func TestInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestInfoStream(t *testing.T) {
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	input := "first\r\n\nbad\nsecond\n"
+
+	mockParser := new(MockDataParser)
+	mockParser.On("Parse", "first").Return(nil).Once()
+	mockParser.On("ActionInfo").Return("info first", nil).Once()
+	mockParser.On("Parse", "bad").Return(errors.New("broken")).Once()
+	mockParser.On("Parse", "second").Return(nil).Once()
+	mockParser.On("ActionInfo").Return("info second", nil).Once()
+
+	var out bytes.Buffer
+	err := InfoStream(strings.NewReader(input), &out, mockParser)
+	require.NoError(t, err)
+
+	mockParser.AssertExpectations(t)
+	assert.Equal(t, "info first\ninfo second\n", out.String(), "в вывод должны попасть только успешно обработанные записи")
+	assert.Contains(t, logBuf.String(), "broken", "ошибка парсинга должна попасть в лог")
+}
+
+func TestInfoStreamLineTooLong(t *testing.T) {
+	input := strings.Repeat("x", maxLineSize+1)
+
+	err := InfoStream(strings.NewReader(input), &bytes.Buffer{}, new(MockDataParser))
+	require.Error(t, err, "слишком длинная строка должна приводить к ошибке чтения")
+}
+
+func TestProcess(t *testing.T) {
+	parseErr := errors.New("bad parse")
+	infoErr := errors.New("bad info")
+
+	mockParser := new(MockDataParser)
+	mockParser.On("Parse", "ok").Return(nil).Once()
+	mockParser.On("ActionInfo").Return("info ok", nil).Once()
+	mockParser.On("Parse", "broken").Return(parseErr).Once()
+	mockParser.On("Parse", "no info").Return(nil).Once()
+	mockParser.On("ActionInfo").Return("", infoErr).Once()
+
+	var got []Result
+	summary, err := Process([]string{"ok", "broken", "no info"}, mockParser, func(r Result) error {
+		got = append(got, r)
+		return nil
+	})
+	require.NoError(t, err)
+
+	mockParser.AssertExpectations(t)
+	assert.Equal(t, Summary{Total: 3, Succeeded: 1, Failed: 2}, summary)
+	assert.Equal(t, []Result{
+		{Index: 0, Input: "ok", Info: "info ok"},
+		{Index: 1, Input: "broken", Err: parseErr, Stage: StageParse},
+		{Index: 2, Input: "no info", Err: infoErr, Stage: StageInfo},
+	}, got)
+}
+
+func TestProcessStopsOnHandlerError(t *testing.T) {
+	stop := errors.New("stop")
+
+	mockParser := new(MockDataParser)
+	mockParser.On("Parse", "first").Return(nil).Once()
+	mockParser.On("ActionInfo").Return("info", nil).Once()
+
+	summary, err := Process([]string{"first", "second"}, mockParser, func(Result) error {
+		return stop
+	})
+
+	require.ErrorIs(t, err, stop)
+	mockParser.AssertExpectations(t)
+	assert.Equal(t, Summary{Total: 1, Succeeded: 1}, summary)
+}
+
+func TestProcessStreamIndex(t *testing.T) {
+	mockParser := new(MockDataParser)
+	mockParser.On("Parse", "a").Return(nil)
+	mockParser.On("Parse", "b").Return(nil)
+	mockParser.On("ActionInfo").Return("info", nil)
+
+	var indexes []int
+	summary, err := ProcessStream(strings.NewReader("a\n\nb\n"), mockParser, func(r Result) error {
+		indexes = append(indexes, r.Index)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{0, 2}, indexes, "индекс должен совпадать с номером строки")
+	assert.Equal(t, Summary{Total: 2, Succeeded: 2}, summary)
+}
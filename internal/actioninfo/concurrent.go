@@ -0,0 +1,174 @@
+package actioninfo
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// windowFactor ограничивает число записей, находящихся в обработке
+// одновременно: не больше windowFactor записей на один обработчик.
+// Это не даёт буферу упорядочивания расти без ограничений, если
+// одна из записей обрабатывается долго.
+const windowFactor = 4
+
+// ParserFactory создаёт новый экземпляр парсера. Реализации DataParser
+// хранят состояние, поэтому каждый обработчик получает свой экземпляр.
+type ParserFactory func() DataParser
+
+// job — запись, переданная обработчику. seq — сквозной номер задачи,
+// по нему восстанавливается исходный порядок результатов.
+type job struct {
+	seq   int
+	index int
+	entry string
+}
+
+type jobResult struct {
+	seq int
+	res Result
+}
+
+// ProcessConcurrent обрабатывает набор записей в workers потоков и передаёт
+// результаты в handle в исходном порядке. Обработка прекращается при отмене
+// ctx или при ошибке handle.
+func ProcessConcurrent(ctx context.Context, dataset []string, workers int, newParser ParserFactory, handle Handler) (Summary, error) {
+	return processConcurrent(ctx, workers, newParser, handle, func(emit func(index int, entry string) bool) error {
+		for i, entry := range dataset {
+			if !emit(i, entry) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// ProcessStreamConcurrent — потоковый вариант ProcessConcurrent: записи
+// построчно читаются из r, пустые строки пропускаются.
+func ProcessStreamConcurrent(ctx context.Context, r io.Reader, workers int, newParser ParserFactory, handle Handler) (Summary, error) {
+	return processConcurrent(ctx, workers, newParser, handle, func(emit func(index int, entry string) bool) error {
+		scanner := newScanner(r)
+		for line := 0; scanner.Scan(); line++ {
+			entry := strings.TrimSuffix(scanner.Text(), "\r")
+			if entry == "" {
+				continue
+			}
+			if !emit(line, entry) {
+				return nil
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return i18n.Errorf("ошибка чтения данных: %w", err)
+		}
+		return nil
+	})
+}
+
+func processConcurrent(parent context.Context, workers int, newParser ParserFactory, handle Handler,
+	feed func(emit func(index int, entry string) bool) error) (Summary, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	jobs := make(chan job)
+	results := make(chan jobResult, workers)
+	window := make(chan struct{}, workers*windowFactor)
+
+	var feedErr error
+	feedDone := make(chan struct{})
+	go func() {
+		defer close(feedDone)
+		defer close(jobs)
+
+		seq := 0
+		feedErr = feed(func(index int, entry string) bool {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return false
+			}
+
+			select {
+			case jobs <- job{seq: seq, index: index, entry: entry}:
+				seq++
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			dp := newParser()
+			for j := range jobs {
+				res := processEntry(j.index, j.entry, dp)
+				select {
+				case results <- jobResult{seq: j.seq, res: res}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var (
+		summary   Summary
+		handleErr error
+		next      int
+		pending   = make(map[int]Result)
+	)
+
+	for jr := range results {
+		if handleErr != nil {
+			continue
+		}
+
+		pending[jr.seq] = jr.res
+		for {
+			res, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			<-window
+
+			summary.Add(res)
+			if err := handle(res); err != nil {
+				handleErr = err
+				cancel()
+				break
+			}
+		}
+	}
+
+	<-feedDone
+
+	switch {
+	case handleErr != nil:
+		return summary, handleErr
+	case parent.Err() != nil:
+		return summary, parent.Err()
+	case feedErr != nil:
+		return summary, feedErr
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,117 @@
+package actioninfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// delayParser — парсер с состоянием: Parse запоминает число, ActionInfo
+// возвращает его после задержки, обратной значению, чтобы результаты
+// приходили из обработчиков не по порядку.
+type delayParser struct {
+	n int
+}
+
+func (p *delayParser) Parse(data string) error {
+	n, err := strconv.Atoi(data)
+	if err != nil {
+		return err
+	}
+	p.n = n
+	return nil
+}
+
+func (p *delayParser) ActionInfo() (string, error) {
+	time.Sleep(time.Duration(10-p.n%10) * time.Millisecond)
+	return fmt.Sprintf("n=%d", p.n), nil
+}
+
+func newDelayParser() DataParser {
+	return &delayParser{}
+}
+
+func TestProcessConcurrentOrder(t *testing.T) {
+	var dataset, want []string
+	for i := range 50 {
+		dataset = append(dataset, strconv.Itoa(i))
+		want = append(want, fmt.Sprintf("n=%d", i))
+	}
+	dataset = append(dataset, "bad")
+
+	var got []string
+	var indexes []int
+	summary, err := ProcessConcurrent(context.Background(), dataset, 8, newDelayParser, func(r Result) error {
+		indexes = append(indexes, r.Index)
+		if r.OK() {
+			got = append(got, r.Info)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, want, got, "результаты должны идти в исходном порядке")
+	assert.Equal(t, Summary{Total: 51, Succeeded: 50, Failed: 1}, summary)
+	for i, idx := range indexes {
+		assert.Equal(t, i, idx)
+	}
+}
+
+func TestProcessStreamConcurrent(t *testing.T) {
+	var got []Result
+	summary, err := ProcessStreamConcurrent(context.Background(), strings.NewReader("3\n\n1\n2\n"), 3, newDelayParser, func(r Result) error {
+		got = append(got, r)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, Summary{Total: 3, Succeeded: 3}, summary)
+	require.Len(t, got, 3)
+	assert.Equal(t, "n=3", got[0].Info)
+	assert.Equal(t, 2, got[1].Index, "индекс должен совпадать с номером строки")
+	assert.Equal(t, "n=1", got[1].Info)
+	assert.Equal(t, "n=2", got[2].Info)
+}
+
+func TestProcessConcurrentCancel(t *testing.T) {
+	dataset := make([]string, 1000)
+	for i := range dataset {
+		dataset[i] = "1"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	summary, err := ProcessConcurrent(ctx, dataset, 4, newDelayParser, func(Result) error {
+		count++
+		if count == 5 {
+			cancel()
+		}
+		return nil
+	})
+
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, summary.Total, len(dataset), "после отмены обработка должна прекратиться")
+}
+
+func TestProcessConcurrentHandlerError(t *testing.T) {
+	stop := errors.New("stop")
+
+	summary, err := ProcessConcurrent(context.Background(), []string{"1", "2", "3", "4"}, 2, newDelayParser, func(r Result) error {
+		if r.Index == 1 {
+			return stop
+		}
+		return nil
+	})
+
+	require.ErrorIs(t, err, stop)
+	assert.Equal(t, 2, summary.Total)
+}
@@ -0,0 +1,245 @@
+// Package aggregate подводит итоги записей об активности по дням,
+// неделям и месяцам: шаги, дистанцию, активное время и калории —
+// всего и по каждому типу активности, — а также расход энергии.
+package aggregate
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/report"
+)
+
+// Period — период, за который подводятся итоги.
+type Period string
+
+const (
+	Day   Period = "day"
+	Week  Period = "week"
+	Month Period = "month"
+)
+
+// Periods — поддерживаемые периоды.
+var Periods = []Period{Day, Week, Month}
+
+// ParsePeriod распознаёт период по названию без учёта регистра.
+func ParsePeriod(s string) (Period, error) {
+	p := Period(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Periods {
+		if p == known {
+			return p, nil
+		}
+	}
+	return "", i18n.Errorf("неизвестный период: %s", s)
+}
+
+// Start возвращает начало периода, которому принадлежит t, в часовом
+// поясе t. Неделя начинается с понедельника.
+func (p Period) Start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch p {
+	case Week:
+		// Воскресенье в time.Weekday нулевое, а неделя начинается с понедельника.
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case Month:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// End возвращает начало следующего периода после периода, который
+// начинается в start.
+func (p Period) End(start time.Time) time.Time {
+	switch p {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Totals — суммарные показатели набора записей.
+type Totals struct {
+	Records  int           // количество записей.
+	Steps    int           // количество шагов.
+	Distance float64       // дистанция, км.
+	Duration time.Duration // активное время.
+	Calories float64       // потраченные калории, ккал.
+}
+
+// Add добавляет показатели записи r к итогам.
+func (t *Totals) Add(r report.Result) {
+	t.Records++
+	t.Steps += r.Steps
+	t.Distance += r.Distance
+	t.Duration += r.Duration
+	t.Calories += r.Calories
+}
+
+// TypeTotals — итоги по одному типу активности. Для дневной активности
+// Kind равен report.KindDaySteps, а Type пуст; для тренировок Type —
+// каноническое название типа из реестра, не зависящее от языка.
+type TypeTotals struct {
+	Kind string
+	Type string
+	Totals
+}
+
+// Bucket — итоги за один период.
+type Bucket struct {
+	Period Period
+	Start  time.Time // начало периода.
+	Totals
+	ByType []TypeTotals // сначала дневная активность, затем тренировки по названию.
+
+	// EnergyExpenditure — расход энергии, ккал, за дни периода, для
+	// которых известен базовый обмен: базовый обмен каждого дня
+	// учитывается один раз, к нему прибавляются калории всех записей
+	// этого дня. EnergyDays — количество таких дней; 0 — расход
+	// не рассчитан.
+	EnergyExpenditure float64
+	EnergyDays        int
+}
+
+// End возвращает начало следующего периода.
+func (b Bucket) End() time.Time {
+	return b.Period.End(b.Start)
+}
+
+// Aggregator накапливает записи и раскладывает их по периодам.
+// Записи дневной активности и тренировок суммируются независимо:
+// шаги тренировки не считаются частью дневной активности.
+type Aggregator struct {
+	period   Period
+	location *time.Location
+	buckets  map[dateKey]*bucket
+}
+
+type dateKey struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+type typeKey struct {
+	kind, typ string
+}
+
+type bucket struct {
+	start  time.Time
+	totals Totals
+	byType map[typeKey]*Totals
+	days   map[dateKey]*day
+}
+
+// day — данные одного дня для расчёта расхода энергии. Базовый обмен
+// берётся из последней записи дня, в которой он есть: параметры тела
+// могли измениться в течение дня.
+type day struct {
+	bmr      float64
+	bmrTime  time.Time
+	calories float64
+}
+
+// New создаёт Aggregator для периода p. Если loc не nil, границы периодов
+// считаются в этом часовом поясе, иначе — в часовом поясе каждой записи.
+func New(p Period, loc *time.Location) *Aggregator {
+	return &Aggregator{
+		period:   p,
+		location: loc,
+		buckets:  make(map[dateKey]*bucket),
+	}
+}
+
+// Add добавляет запись. Запись без времени отнести к периоду нельзя,
+// для неё возвращается ошибка.
+func (a *Aggregator) Add(r report.Result) error {
+	if r.Time.IsZero() {
+		return i18n.Errorf("у записи нет времени, её нельзя отнести к периоду")
+	}
+
+	t := r.Time
+	if a.location != nil {
+		t = t.In(a.location)
+	}
+	start := a.period.Start(t)
+
+	y, m, d := start.Date()
+	key := dateKey{y, m, d}
+	b, ok := a.buckets[key]
+	if !ok {
+		b = &bucket{start: start, byType: make(map[typeKey]*Totals), days: make(map[dateKey]*day)}
+		a.buckets[key] = b
+	}
+
+	y, m, d = t.Date()
+	dk := dateKey{y, m, d}
+	dd, ok := b.days[dk]
+	if !ok {
+		dd = &day{}
+		b.days[dk] = dd
+	}
+	dd.calories += r.Calories
+	if r.BMR > 0 && !r.Time.Before(dd.bmrTime) {
+		dd.bmr, dd.bmrTime = r.BMR, r.Time
+	}
+
+	b.totals.Add(r)
+
+	// Тренировки группируются по каноническому названию типа, а не по
+	// названию для вывода.
+	tk := typeKey{kind: r.Kind, typ: r.Type}
+	totals, ok := b.byType[tk]
+	if !ok {
+		totals = &Totals{}
+		b.byType[tk] = totals
+	}
+	totals.Add(r)
+
+	return nil
+}
+
+// AddProvider добавляет запись, рассчитанную p.
+func (a *Aggregator) AddProvider(p report.Provider) error {
+	r, err := p.Result()
+	if err != nil {
+		return err
+	}
+	return a.Add(r)
+}
+
+// Buckets возвращает итоги по периодам в хронологическом порядке.
+func (a *Aggregator) Buckets() []Bucket {
+	buckets := make([]Bucket, 0, len(a.buckets))
+	for _, b := range a.buckets {
+		out := Bucket{Period: a.period, Start: b.start, Totals: b.totals}
+		for _, d := range b.days {
+			if d.bmr > 0 {
+				out.EnergyExpenditure += d.bmr + d.calories
+				out.EnergyDays++
+			}
+		}
+		for tk, totals := range b.byType {
+			out.ByType = append(out.ByType, TypeTotals{Kind: tk.kind, Type: tk.typ, Totals: *totals})
+		}
+		sort.Slice(out.ByType, func(i, j int) bool {
+			ti, tj := out.ByType[i], out.ByType[j]
+			if ti.Kind != tj.Kind {
+				return ti.Kind == report.KindDaySteps
+			}
+			return ti.Type < tj.Type
+		})
+		buckets = append(buckets, out)
+	}
+
+	// Сравниваются календарные даты, а не моменты времени: записи
+	// из разных часовых поясов за одну дату попадают в один период.
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Format(time.DateOnly) < buckets[j].Start.Format(time.DateOnly)
+	})
+	return buckets
+}
@@ -0,0 +1,137 @@
+package aggregate
+
+import (
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/daysteps"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(y int, m time.Month, d, h int) time.Time {
+	return time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+}
+
+func TestParsePeriod(t *testing.T) {
+	p, err := ParsePeriod(" Week ")
+	require.NoError(t, err)
+	assert.Equal(t, Week, p)
+
+	_, err = ParsePeriod("year")
+	assert.Error(t, err)
+}
+
+func TestPeriodStart(t *testing.T) {
+	// 2024-05-01 — среда.
+	wednesday := time.Date(2024, 5, 1, 18, 45, 0, 0, time.UTC)
+	sunday := time.Date(2024, 5, 5, 23, 59, 0, 0, time.UTC)
+
+	assert.Equal(t, date(2024, 5, 1, 0), Day.Start(wednesday))
+	assert.Equal(t, date(2024, 4, 29, 0), Week.Start(wednesday))
+	assert.Equal(t, date(2024, 4, 29, 0), Week.Start(sunday), "воскресенье относится к неделе, начавшейся в понедельник")
+	assert.Equal(t, date(2024, 5, 1, 0), Month.Start(wednesday))
+
+	assert.Equal(t, date(2024, 5, 2, 0), Day.End(date(2024, 5, 1, 0)))
+	assert.Equal(t, date(2024, 5, 6, 0), Week.End(date(2024, 4, 29, 0)))
+	assert.Equal(t, date(2024, 6, 1, 0), Month.End(date(2024, 5, 1, 0)))
+}
+
+func TestAggregator(t *testing.T) {
+	a := New(Week, nil)
+
+	records := []report.Result{
+		{Kind: report.KindDaySteps, Time: date(2024, 4, 29, 8), Steps: 1000, Distance: 0.5, Duration: 30 * time.Minute, Calories: 20},
+		{Kind: report.KindTraining, Type: "Бег", Time: date(2024, 5, 1, 7), Steps: 3000, Distance: 2, Duration: 15 * time.Minute, Calories: 150},
+		{Kind: report.KindTraining, Type: "Бег", Time: date(2024, 5, 5, 7), Steps: 5000, Distance: 4, Duration: 25 * time.Minute, Calories: 250},
+		{Kind: report.KindTraining, Type: "Ходьба", Time: date(2024, 5, 6, 7), Steps: 2000, Distance: 1, Duration: 20 * time.Minute, Calories: 60},
+		{Kind: report.KindDaySteps, Time: date(2024, 4, 22, 8), Steps: 500, Distance: 0.25, Duration: 10 * time.Minute, Calories: 10},
+	}
+	for _, r := range records {
+		require.NoError(t, a.Add(r))
+	}
+	assert.Error(t, a.Add(report.Result{Kind: report.KindDaySteps, Steps: 100}), "запись без времени")
+
+	buckets := a.Buckets()
+	require.Len(t, buckets, 3)
+
+	assert.Equal(t, date(2024, 4, 22, 0), buckets[0].Start)
+	assert.Equal(t, date(2024, 4, 29, 0), buckets[1].Start)
+	assert.Equal(t, date(2024, 5, 6, 0), buckets[2].Start)
+
+	week := buckets[1]
+	assert.Equal(t, Week, week.Period)
+	assert.Equal(t, date(2024, 5, 6, 0), week.End())
+	assert.Equal(t, Totals{Records: 3, Steps: 9000, Distance: 6.5, Duration: 70 * time.Minute, Calories: 420}, week.Totals)
+	assert.Equal(t, []TypeTotals{
+		{Kind: report.KindDaySteps, Totals: Totals{Records: 1, Steps: 1000, Distance: 0.5, Duration: 30 * time.Minute, Calories: 20}},
+		{Kind: report.KindTraining, Type: "Бег", Totals: Totals{Records: 2, Steps: 8000, Distance: 6, Duration: 40 * time.Minute, Calories: 400}},
+	}, week.ByType)
+}
+
+func TestAggregatorLocation(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	// В UTC это ещё 30 апреля, а в Москве уже 1 мая.
+	r := report.Result{Kind: report.KindDaySteps, Time: time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC), Steps: 100}
+
+	utc := New(Day, nil)
+	require.NoError(t, utc.Add(r))
+	assert.Equal(t, date(2024, 4, 30, 0), utc.Buckets()[0].Start)
+
+	local := New(Day, msk)
+	require.NoError(t, local.Add(r))
+	assert.True(t, time.Date(2024, 5, 1, 0, 0, 0, 0, msk).Equal(local.Buckets()[0].Start))
+}
+
+func TestAggregatorEnergyExpenditure(t *testing.T) {
+	a := New(Week, nil)
+
+	records := []report.Result{
+		{Kind: report.KindDaySteps, Time: date(2024, 4, 29, 8), Calories: 100, BMR: 1700},
+		{Kind: report.KindDaySteps, Time: date(2024, 4, 29, 20), Calories: 50, BMR: 1690},
+		{Kind: report.KindTraining, Type: "Бег", Time: date(2024, 4, 29, 18), Calories: 300},
+		{Kind: report.KindDaySteps, Time: date(2024, 4, 30, 8), Calories: 80, BMR: 1690},
+		{Kind: report.KindTraining, Type: "Бег", Time: date(2024, 5, 1, 7), Calories: 400},
+	}
+	for _, r := range records {
+		require.NoError(t, a.Add(r))
+	}
+
+	buckets := a.Buckets()
+	require.Len(t, buckets, 1)
+	assert.Equal(t, 2, buckets[0].EnergyDays, "за 1 мая базовый обмен неизвестен")
+	assert.Equal(t, 1690+100+50+300+1690+80.0, buckets[0].EnergyExpenditure,
+		"базовый обмен дня учитывается один раз, по последней записи")
+
+	day := New(Day, nil)
+	require.NoError(t, day.Add(records[4]))
+	assert.Zero(t, day.Buckets()[0].EnergyDays)
+}
+
+func TestAddProvider(t *testing.T) {
+	person := personaldata.Personal{Weight: 75, Height: 1.75}
+	a := New(Day, nil)
+
+	ds := &daysteps.DaySteps{Personal: person}
+	require.NoError(t, ds.Parse("2024-05-01T08:00:00Z,6000,1h"))
+	require.NoError(t, a.AddProvider(ds))
+
+	tr := &trainings.Training{Personal: person}
+	require.NoError(t, tr.Parse("2024-05-01T18:00:00Z,3000,Бег,30m"))
+	require.NoError(t, a.AddProvider(tr))
+
+	buckets := a.Buckets()
+	require.Len(t, buckets, 1)
+	assert.Equal(t, 2, buckets[0].Records)
+	assert.Equal(t, 9000, buckets[0].Steps)
+	assert.Equal(t, 90*time.Minute, buckets[0].Duration)
+	require.Len(t, buckets[0].ByType, 2)
+	assert.Equal(t, "Бег", buckets[0].ByType[1].Type)
+
+	tr.TrainingType = "Плавание"
+	assert.Error(t, a.AddProvider(tr), "ошибка расчёта должна возвращаться")
+}
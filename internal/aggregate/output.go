@@ -0,0 +1,193 @@
+package aggregate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/units"
+)
+
+// Text возвращает текстовый отчёт об итогах за период на текущем языке
+// и в текущей системе единиц.
+func Text(b Bucket) string {
+	var text string
+	switch b.Period {
+	case Week:
+		text = i18n.T("Неделя с %s\n", b.Start.Format(time.DateOnly))
+	case Month:
+		text = i18n.T("Месяц %s\n", b.Start.Format("2006-01"))
+	default:
+		text = i18n.T("День %s\n", b.Start.Format(time.DateOnly))
+	}
+
+	sys := units.Current()
+	distance, distanceUnit := sys.Distance(b.Distance)
+	text += i18n.T("Записей: %d\nШагов: %d\nДистанция: %.2f %s.\nАктивное время: %.2f ч.\nСожгли калорий: %.2f\n",
+		b.Records, b.Steps, distance, distanceUnit, b.Duration.Hours(), b.Calories)
+	switch {
+	case b.EnergyDays == 0:
+	case b.Period == Day:
+		text += i18n.T("Суточный расход энергии: %.2f ккал.\n", b.EnergyExpenditure)
+	default:
+		text += i18n.T("Расход энергии: %.2f ккал (дней с базовым обменом: %d)\n", b.EnergyExpenditure, b.EnergyDays)
+	}
+
+	for _, tt := range b.ByType {
+		distance, distanceUnit := sys.Distance(tt.Distance)
+		text += i18n.T("  %s: записей %d, шагов %d, %.2f %s, %.2f ч., %.2f ккал\n",
+			typeTitle(tt), tt.Records, tt.Steps, distance, distanceUnit, tt.Duration.Hours(), tt.Calories)
+	}
+	return text
+}
+
+func typeTitle(tt TypeTotals) string {
+	if tt.Kind == report.KindDaySteps {
+		return i18n.T("Активность в течение дня")
+	}
+	return i18n.T(tt.Type)
+}
+
+// jsonTotals — представление Totals в JSON; продолжительность, как
+// и в report.Result, записывается строкой и в часах.
+type jsonTotals struct {
+	Records       int     `json:"records"`
+	Steps         int     `json:"steps"`
+	Distance      float64 `json:"distance_km"`
+	Duration      string  `json:"duration"`
+	DurationHours float64 `json:"duration_hours"`
+	Calories      float64 `json:"calories_kcal"`
+}
+
+type jsonTypeTotals struct {
+	Kind string `json:"kind"`
+	Type string `json:"type,omitempty"`
+	jsonTotals
+}
+
+type jsonBucket struct {
+	Period string `json:"period"`
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	jsonTotals
+	EnergyExpenditure float64          `json:"energy_expenditure_kcal,omitempty"`
+	EnergyDays        int              `json:"energy_expenditure_days,omitempty"`
+	ByType            []jsonTypeTotals `json:"by_type"`
+}
+
+func newJSONTotals(t Totals) jsonTotals {
+	return jsonTotals{
+		Records:       t.Records,
+		Steps:         t.Steps,
+		Distance:      t.Distance,
+		Duration:      t.Duration.String(),
+		DurationHours: t.Duration.Hours(),
+		Calories:      t.Calories,
+	}
+}
+
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	jb := jsonBucket{
+		Period:            string(b.Period),
+		Start:             b.Start.Format(time.DateOnly),
+		End:               b.End().Format(time.DateOnly),
+		jsonTotals:        newJSONTotals(b.Totals),
+		EnergyExpenditure: b.EnergyExpenditure,
+		EnergyDays:        b.EnergyDays,
+		ByType:            []jsonTypeTotals{},
+	}
+	for _, tt := range b.ByType {
+		jb.ByType = append(jb.ByType, jsonTypeTotals{Kind: tt.Kind, Type: tt.Type, jsonTotals: newJSONTotals(tt.Totals)})
+	}
+	return json.Marshal(jb)
+}
+
+// csvHeader — заголовок CSV. Для каждого периода пишется строка
+// с kind=total и по строке на каждый тип активности; расход энергии
+// заполняется только в строке kind=total.
+var csvHeader = []string{
+	"period", "start", "end", "kind", "type", "records", "steps",
+	"distance_km", "duration", "duration_hours", "calories_kcal",
+	"energy_expenditure_kcal",
+}
+
+// kindTotal — значение kind в строке CSV с итогами за весь период.
+const kindTotal = "total"
+
+// Write записывает итоги в формате f.
+func Write(w io.Writer, f report.Format, buckets []Bucket) error {
+	switch f {
+	case report.FormatText:
+		for _, b := range buckets {
+			if _, err := fmt.Fprintln(w, Text(b)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case report.FormatJSON:
+		if buckets == nil {
+			buckets = []Bucket{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(buckets)
+	case report.FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, b := range buckets {
+			if err := enc.Encode(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	case report.FormatCSV:
+		return writeCSV(w, buckets)
+	}
+	return i18n.Errorf("неизвестный формат вывода: %s", f)
+}
+
+func writeCSV(w io.Writer, buckets []Bucket) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, b := range buckets {
+		start, end := b.Start.Format(time.DateOnly), b.End().Format(time.DateOnly)
+		var energy string
+		if b.EnergyDays > 0 {
+			energy = formatFloat(b.EnergyExpenditure)
+		}
+		if err := cw.Write(append(csvRecord(b.Period, start, end, kindTotal, "", b.Totals), energy)); err != nil {
+			return err
+		}
+		for _, tt := range b.ByType {
+			if err := cw.Write(append(csvRecord(b.Period, start, end, tt.Kind, tt.Type, tt.Totals), "")); err != nil {
+				return err
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvRecord(p Period, start, end, kind, typ string, t Totals) []string {
+	return []string{
+		string(p), start, end, kind, typ,
+		strconv.Itoa(t.Records),
+		strconv.Itoa(t.Steps),
+		formatFloat(t.Distance),
+		t.Duration.String(),
+		formatFloat(t.Duration.Hours()),
+		formatFloat(t.Calories),
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,110 @@
+package aggregate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/report"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleBucket = Bucket{
+	Period: Week,
+	Start:  date(2024, 4, 29, 0),
+	Totals: Totals{Records: 2, Steps: 4000, Distance: 2.5, Duration: 45 * time.Minute, Calories: 170},
+	ByType: []TypeTotals{
+		{Kind: report.KindDaySteps, Totals: Totals{Records: 1, Steps: 1000, Distance: 0.5, Duration: 30 * time.Minute, Calories: 20}},
+		{Kind: report.KindTraining, Type: "Бег", Totals: Totals{Records: 1, Steps: 3000, Distance: 2, Duration: 15 * time.Minute, Calories: 150}},
+	},
+}
+
+func TestText(t *testing.T) {
+	want := "Неделя с 2024-04-29\n" +
+		"Записей: 2\nШагов: 4000\nДистанция: 2.50 км.\nАктивное время: 0.75 ч.\nСожгли калорий: 170.00\n" +
+		"  Активность в течение дня: записей 1, шагов 1000, 0.50 км, 0.50 ч., 20.00 ккал\n" +
+		"  Бег: записей 1, шагов 3000, 2.00 км, 0.25 ч., 150.00 ккал\n"
+	assert.Equal(t, want, Text(sampleBucket))
+
+	day := Bucket{Period: Day, Start: date(2024, 5, 1, 0)}
+	assert.True(t, strings.HasPrefix(Text(day), "День 2024-05-01\n"))
+
+	month := Bucket{Period: Month, Start: date(2024, 5, 1, 0)}
+	assert.True(t, strings.HasPrefix(Text(month), "Месяц 2024-05\n"))
+}
+
+func TestTextLocalizedType(t *testing.T) {
+	defer i18n.SetLocale(i18n.Current())
+	i18n.SetLocale(i18n.EN)
+	assert.Contains(t, Text(sampleBucket), "  Running: 1 records, 3000 steps,")
+}
+
+func TestTextEnergyExpenditure(t *testing.T) {
+	day := Bucket{Period: Day, Start: date(2024, 5, 1, 0), EnergyExpenditure: 1900, EnergyDays: 1}
+	assert.Contains(t, Text(day), "Сожгли калорий: 0.00\nСуточный расход энергии: 1900.00 ккал.\n")
+
+	week := sampleBucket
+	week.EnergyExpenditure, week.EnergyDays = 3570, 2
+	assert.Contains(t, Text(week), "Сожгли калорий: 170.00\nРасход энергии: 3570.00 ккал (дней с базовым обменом: 2)\n")
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report.FormatJSON, []Bucket{sampleBucket}))
+
+	assert.JSONEq(t, `[{
+		"period": "week", "start": "2024-04-29", "end": "2024-05-06",
+		"records": 2, "steps": 4000, "distance_km": 2.5, "duration": "45m0s", "duration_hours": 0.75, "calories_kcal": 170,
+		"by_type": [
+			{"kind": "daysteps", "records": 1, "steps": 1000, "distance_km": 0.5, "duration": "30m0s", "duration_hours": 0.5, "calories_kcal": 20},
+			{"kind": "training", "type": "Бег", "records": 1, "steps": 3000, "distance_km": 2, "duration": "15m0s", "duration_hours": 0.25, "calories_kcal": 150}
+		]
+	}]`, buf.String())
+
+	b := sampleBucket
+	b.EnergyExpenditure, b.EnergyDays = 3570, 2
+	data, err := json.Marshal(b)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"energy_expenditure_kcal":3570,"energy_expenditure_days":2`)
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, report.FormatJSON, nil))
+	assert.JSONEq(t, `[]`, buf.String())
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report.FormatNDJSON, []Bucket{sampleBucket, sampleBucket}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)), line)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report.FormatCSV, []Bucket{sampleBucket}))
+
+	want := "period,start,end,kind,type,records,steps,distance_km,duration,duration_hours,calories_kcal,energy_expenditure_kcal\n" +
+		"week,2024-04-29,2024-05-06,total,,2,4000,2.50,45m0s,0.75,170.00,\n" +
+		"week,2024-04-29,2024-05-06,daysteps,,1,1000,0.50,30m0s,0.50,20.00,\n" +
+		"week,2024-04-29,2024-05-06,training,Бег,1,3000,2.00,15m0s,0.25,150.00,\n"
+	assert.Equal(t, want, buf.String())
+
+	b := sampleBucket
+	b.EnergyExpenditure, b.EnergyDays = 3570, 2
+	buf.Reset()
+	require.NoError(t, Write(&buf, report.FormatCSV, []Bucket{b}))
+	assert.Contains(t, buf.String(), "week,2024-04-29,2024-05-06,total,,2,4000,2.50,45m0s,0.75,170.00,3570.00\n")
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	assert.Error(t, Write(&bytes.Buffer{}, report.Format("xml"), nil))
+}
@@ -0,0 +1,45 @@
+// Package atomicfile записывает файлы так, чтобы при сбое на диске
+// оставалось либо прежнее, либо новое содержимое целиком.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFile записывает data во временный файл рядом с path, сбрасывает
+// его на диск и переименовывает поверх path. При ошибке на любом шаге
+// path остаётся в прежнем состоянии.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Сбрасываем каталог, чтобы переименование пережило сбой питания.
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
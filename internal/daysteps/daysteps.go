@@ -1,58 +1,59 @@
 package daysteps
 
 import (
+	"FINAL-PROJECT-5/internal/i18n"
 	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/report"
 	"FINAL-PROJECT-5/internal/spentenergy"
-	"fmt"
 	"log"
-	"strconv"
-	"strings"
 	"time"
 )
 
 type DaySteps struct {
+	Time     time.Time // время записи; нулевое, если в записи его нет.
 	Steps    int
 	Duration time.Duration
 	personaldata.Personal
+
+	// Estimator — модель расчёта калорий; если nil, используется spentenergy.DefaultEstimator.
+	Estimator spentenergy.Estimator
+	// ParseOptions — режим разбора записей. В режиме по умолчанию, как
+	// и прежде, количество шагов с пробелами по краям отклоняется,
+	// а продолжительность очищается от них.
+	ParseOptions record.ParseOptions
+	// Plausibility — правила проверки правдоподобия; если nil, запись не проверяется.
+	Plausibility *plausibility.Rules
 }
 
+// Parse разбирает запись вида "[время,]шаги,продолжительность" в режиме
+// ParseOptions. Ошибка имеет тип *record.FieldError; её код проверяется
+// через errors.Is.
 func (ds *DaySteps) Parse(datastring string) (err error) {
-	parts := strings.Split(datastring, ",")
-	if len(parts) != 2 {
-		log.Println("Ошибка: нехватка данных")
-		return fmt.Errorf("нехватка данных")
+	recordTime, parts, column, err := ds.ParseOptions.Split(datastring)
+	if err != nil {
+		return err
 	}
-
-	stepsStr := parts[0]
-	if strings.TrimSpace(stepsStr) == "" {
-		return fmt.Errorf("количество шагов не может быть пустым")
+	if len(parts) < 2 {
+		log.Println(i18n.T("Ошибка: нехватка данных"))
+		return record.NewFieldError("", 0, datastring, record.ErrMissingFields, nil)
 	}
-
-	if strings.HasPrefix(stepsStr, " ") || strings.HasSuffix(stepsStr, " ") {
-		return fmt.Errorf("количество шагов не должно содержать пробелов в начале или в конце")
+	if len(parts) > 2 && !ds.ParseOptions.Lenient() {
+		return record.NewFieldError("", column+2, parts[2], record.ErrTooManyFields, nil)
 	}
 
-	steps, err := strconv.Atoi(strings.TrimSpace(stepsStr))
+	steps, err := ds.ParseOptions.Steps(parts[0], column)
 	if err != nil {
-		log.Println("Ошибка при преобразовании количества шагов:", err)
-		return fmt.Errorf("неверный формат количества шагов")
-	}
-
-	if steps <= 0 {
-		return fmt.Errorf("количество шагов должно быть больше нуля")
+		return err
 	}
 
+	ds.Time = recordTime
 	ds.Steps = steps
 
-	durationStr := strings.TrimSpace(parts[1])
-	duration, err := time.ParseDuration(durationStr)
+	duration, err := ds.ParseOptions.Duration(ds.ParseOptions.TrimDefault(parts[1]), column+1)
 	if err != nil {
-		log.Println("Ошибка при преобразовании продолжительности:", err)
-		return fmt.Errorf("неверный формат продолжительности")
-	}
-
-	if duration <= 0 {
-		return fmt.Errorf("продолжительность должна быть больше нуля")
+		return err
 	}
 	ds.Duration = duration
 
@@ -62,14 +63,61 @@ func (ds *DaySteps) Parse(datastring string) (err error) {
 // Метод для интерфейса
 
 func (ds DaySteps) ActionInfo() (string, error) {
-	distance := spentenergy.Distance(ds.Steps, ds.Personal.Height)
-
-	calories, err := spentenergy.WalkingSpentCalories(ds.Steps, ds.Personal.Weight, ds.Personal.Height, ds.Duration)
+	r, err := ds.Result()
 	if err != nil {
 		return "", err
 	}
+	return report.Text(r), nil
+}
 
-	result := fmt.Sprintf("Количество шагов: %d.\nДистанция составила %.2f км.\nВы сожгли %.2f ккал.\n",
-		ds.Steps, distance, calories)
-	return result, nil
+// Result возвращает рассчитанные показатели дневной активности.
+// Параметры тела берутся на момент записи; см. personaldata.Personal.AsOf.
+// Неправдоподобная запись отклоняется ошибкой plausibility.Violation
+// или принимается с предупреждениями в зависимости от Plausibility.
+func (ds DaySteps) Result() (report.Result, error) {
+	person := ds.Personal.AsOf(ds.Time)
+	calories, err := ds.estimator().Calories(spentenergy.Input{
+		Activity: spentenergy.Walking,
+		Steps:    ds.Steps,
+		Duration: ds.Duration,
+	}, person)
+	if err != nil {
+		return report.Result{}, err
+	}
+
+	r := report.Result{
+		Kind:     report.KindDaySteps,
+		Time:     ds.Time,
+		Steps:    ds.Steps,
+		Duration: ds.Duration,
+		Distance: spentenergy.Distance(ds.Steps, person.Height),
+		Speed:    spentenergy.MeanSpeed(ds.Steps, person.Height, ds.Duration),
+		Calories: calories,
+	}
+
+	// Если известны возраст и пол, добавляем базовый обмен. Суточный
+	// расход энергии складывается из него и калорий всех записей за день
+	// и считается в aggregate: у одной записи его не узнать.
+	if bmr, err := person.BMRMifflinStJeor(); err == nil {
+		r.BMR = bmr
+	}
+
+	r.Warnings, err = ds.Plausibility.Check(plausibility.Record{
+		Kind:     spentenergy.Walking,
+		Steps:    r.Steps,
+		Duration: r.Duration,
+		Speed:    r.Speed,
+	})
+	if err != nil {
+		return report.Result{}, err
+	}
+
+	return r, nil
+}
+
+func (ds DaySteps) estimator() spentenergy.Estimator {
+	if ds.Estimator != nil {
+		return ds.Estimator
+	}
+	return spentenergy.DefaultEstimator
 }
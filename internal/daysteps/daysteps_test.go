@@ -5,6 +5,9 @@ import (
 	"time"
 
 	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/spentenergy"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -201,8 +204,29 @@ func (suite *DayStepsTestSuite) TestParsePackage() {
 			wantErr:      true,
 		},
 		{
-			name:         "неверная продолжительность - пропущена единица измерения",
+			name:         "продолжительность без единицы измерения - минуты",
 			input:        "678,30",
+			wantSteps:    678,
+			wantDuration: 30 * time.Minute,
+			wantErr:      false,
+		},
+		{
+			name:         "продолжительность в формате ЧЧ:ММ:СС",
+			input:        "678,12:40:00",
+			wantSteps:    678,
+			wantDuration: 12*time.Hour + 40*time.Minute,
+			wantErr:      false,
+		},
+		{
+			name:         "продолжительность в формате ISO 8601",
+			input:        "678,PT45M",
+			wantSteps:    678,
+			wantDuration: 45 * time.Minute,
+			wantErr:      false,
+		},
+		{
+			name:         "неверная продолжительность - минуты больше 59",
+			input:        "678,1:75:00",
 			wantSteps:    0,
 			wantDuration: 0,
 			wantErr:      true,
@@ -225,6 +249,107 @@ func (suite *DayStepsTestSuite) TestParsePackage() {
 	}
 }
 
+func (suite *DayStepsTestSuite) TestParseErrors() {
+	tests := []struct {
+		input   string
+		code    error
+		field   string
+		column  int
+		value   string
+		message string
+	}{
+		{"678", record.ErrMissingFields, "", 0, "678", "нехватка данных"},
+		{"abc,1h", record.ErrInvalidSteps, record.FieldSteps, 1, "abc", "неверный формат количества шагов"},
+		{" 678,1h", record.ErrInvalidSteps, record.FieldSteps, 1, " 678", "количество шагов не должно содержать пробелов в начале или в конце"},
+		{"0,1h", record.ErrNotPositive, record.FieldSteps, 1, "0", "количество шагов должно быть больше нуля"},
+		{"678,abc", record.ErrInvalidDuration, record.FieldDuration, 2, "abc", "неверный формат продолжительности"},
+		{"2024-05-01T07:30:00Z,678,-1h", record.ErrNotPositive, record.FieldDuration, 3, "-1h", "продолжительность должна быть больше нуля"},
+		{"2024-05-01,678,1h", record.ErrInvalidTime, record.FieldTime, 1, "2024-05-01", `неверный формат времени записи: "2024-05-01"`},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.input, func() {
+			err := (&DaySteps{}).Parse(tt.input)
+			assert.ErrorIs(suite.T(), err, tt.code)
+
+			var fe *record.FieldError
+			require.ErrorAs(suite.T(), err, &fe)
+			assert.Equal(suite.T(), tt.field, fe.Field)
+			assert.Equal(suite.T(), tt.column, fe.Column)
+			assert.Equal(suite.T(), tt.value, fe.Value)
+			assert.EqualError(suite.T(), err, tt.message)
+		})
+	}
+}
+
+func (suite *DayStepsTestSuite) TestParseModes() {
+	tests := []struct {
+		input  string
+		def    error // код ошибки в режиме по умолчанию; nil — запись разбирается
+		strict error // код ошибки в строгом режиме; nil — запись разбирается
+		steps  int
+	}{
+		{input: "6000,1h", steps: 6000},
+		{input: " 6000,1h", def: record.ErrInvalidSteps, strict: record.ErrInvalidSteps, steps: 6000},
+		{input: "6 000,1h", def: record.ErrInvalidSteps, strict: record.ErrInvalidSteps, steps: 6000},
+		{input: "1'234,1h", def: record.ErrInvalidSteps, strict: record.ErrInvalidSteps, steps: 1234},
+		{input: "6000, 1h ", strict: record.ErrInvalidDuration, steps: 6000},
+		{input: "6000,1h,", def: record.ErrTooManyFields, strict: record.ErrTooManyFields, steps: 6000},
+		{input: "6000,1h,extra", def: record.ErrTooManyFields, strict: record.ErrTooManyFields, steps: 6000},
+		{input: "2024-05-01T07:30:00Z , 6000 , 1h", def: record.ErrInvalidTime, strict: record.ErrInvalidTime, steps: 6000},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.input, func() {
+			for _, m := range []struct {
+				mode record.Mode
+				want error
+				name string
+			}{
+				{record.Default, tt.def, "режим по умолчанию"},
+				{record.Strict, tt.strict, "строгий режим"},
+			} {
+				ds := &DaySteps{ParseOptions: record.ParseOptions{Mode: m.mode}}
+				err := ds.Parse(tt.input)
+				if m.want != nil {
+					assert.ErrorIs(suite.T(), err, m.want, m.name)
+				} else {
+					assert.NoError(suite.T(), err, m.name)
+				}
+			}
+
+			ds := &DaySteps{ParseOptions: record.ParseOptions{Mode: record.Lenient}}
+			require.NoError(suite.T(), ds.Parse(tt.input), "нестрогий режим")
+			assert.Equal(suite.T(), tt.steps, ds.Steps)
+			assert.Equal(suite.T(), time.Hour, ds.Duration)
+		})
+	}
+
+	ds := &DaySteps{ParseOptions: record.ParseOptions{Mode: record.Lenient}}
+	assert.ErrorIs(suite.T(), ds.Parse("6 00,1h"), record.ErrInvalidSteps, "неверная группировка разрядов")
+	assert.ErrorIs(suite.T(), ds.Parse("6000,"), record.ErrMissingFields)
+}
+
+func (suite *DayStepsTestSuite) TestParseTime() {
+	ds := &DaySteps{}
+	require.NoError(suite.T(), ds.Parse("2024-05-01T07:30:00+03:00,678,0h50m"))
+	assert.True(suite.T(), time.Date(2024, 5, 1, 4, 30, 0, 0, time.UTC).Equal(ds.Time))
+	assert.Equal(suite.T(), 678, ds.Steps)
+	assert.Equal(suite.T(), 50*time.Minute, ds.Duration)
+
+	require.NoError(suite.T(), ds.Parse("792,1h14m"))
+	assert.True(suite.T(), ds.Time.IsZero(), "время из прошлой записи не должно сохраняться")
+
+	assert.Error(suite.T(), ds.Parse("2024-05-01T07:30:00,678,0h50m"), "метка времени без часового пояса")
+	assert.Error(suite.T(), ds.Parse("2024-05-01T07:30:00Z,678"), "нехватка данных после метки времени")
+
+	require.NoError(suite.T(), ds.Parse("2024-05-01T07:30:00Z,678,0h50m"))
+	ds.Personal = personaldata.Personal{Weight: 75, Height: 1.75}
+	r, err := ds.Result()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ds.Time, r.Time)
+}
+
 func (suite *DayStepsTestSuite) TestDayActionInfo() {
 	tests := []struct {
 		name    string
@@ -419,3 +544,81 @@ func (suite *DayStepsTestSuite) TestDayActionInfo() {
 		})
 	}
 }
+
+func (suite *DayStepsTestSuite) TestActionInfoEstimator() {
+	ds := DaySteps{
+		Steps:    6000,
+		Duration: time.Hour,
+		Personal: personaldata.Personal{Weight: 75.0, Height: 1.75},
+		Estimator: spentenergy.EstimatorFunc(func(in spentenergy.Input, p personaldata.Personal) (float64, error) {
+			assert.Equal(suite.T(), spentenergy.Walking, in.Activity)
+			assert.Equal(suite.T(), 6000, in.Steps)
+			return 42, nil
+		}),
+	}
+
+	got, err := ds.ActionInfo()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Количество шагов: 6000.\nДистанция составила 4.72 км.\nВы сожгли 42.00 ккал.\n", got)
+}
+
+func (suite *DayStepsTestSuite) TestActionInfoBMR() {
+	ds := DaySteps{
+		Steps:    6000,
+		Duration: time.Hour,
+		Personal: personaldata.Personal{Weight: 75.0, Height: 1.75, Age: 30, Sex: personaldata.SexMale},
+	}
+
+	got, err := ds.ActionInfo()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Количество шагов: 6000.\nДистанция составила 4.72 км.\nВы сожгли 177.19 ккал.\nБазовый обмен: 1698.75 ккал/сут.\n", got)
+}
+
+func (suite *DayStepsTestSuite) TestResultPlausibility() {
+	rules := plausibility.DefaultRules()
+	person := personaldata.Personal{Weight: 75.0, Height: 1.75}
+
+	ds := DaySteps{Steps: 30000, Duration: time.Hour, Personal: person, Plausibility: &rules}
+	_, err := ds.Result()
+	var v plausibility.Violation
+	require.ErrorAs(suite.T(), err, &v)
+	assert.Equal(suite.T(), plausibility.Cadence, v.Check)
+
+	ds = DaySteps{Steps: 14000, Duration: time.Hour, Personal: person, Plausibility: &rules}
+	got, err := ds.Result()
+	require.NoError(suite.T(), err, "превышение скорости по умолчанию только отмечается")
+	require.Len(suite.T(), got.Warnings, 1)
+	assert.Equal(suite.T(), plausibility.Speed, got.Warnings[0].Check)
+
+	ds.Plausibility = nil
+	got, err = ds.Result()
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), got.Warnings, "без правил запись не проверяется")
+}
+
+func (suite *DayStepsTestSuite) TestResultUsesWeightAtRecordTime() {
+	person := personaldata.Personal{
+		Weight: 70,
+		Height: 1.75,
+		Measurements: []personaldata.Measurement{
+			{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Weight: 90},
+			{Time: time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC), Weight: 80},
+		},
+	}
+
+	ds := &DaySteps{Personal: person}
+	require.NoError(suite.T(), ds.Parse("2024-01-06T00:00:00Z,6000,1h"))
+	got, err := ds.Result()
+	require.NoError(suite.T(), err)
+
+	want, err := DaySteps{Steps: 6000, Duration: time.Hour, Personal: personaldata.Personal{Weight: 85, Height: 1.75}}.Result()
+	require.NoError(suite.T(), err)
+	assert.InDelta(suite.T(), want.Calories, got.Calories, 1e-9, "вес должен интерполироваться на момент записи")
+
+	require.NoError(suite.T(), ds.Parse("6000,1h"))
+	got, err = ds.Result()
+	require.NoError(suite.T(), err)
+	want, err = DaySteps{Steps: 6000, Duration: time.Hour, Personal: personaldata.Personal{Weight: 70, Height: 1.75}}.Result()
+	require.NoError(suite.T(), err)
+	assert.InDelta(suite.T(), want.Calories, got.Calories, 1e-9, "без времени используется вес из профиля")
+}
@@ -0,0 +1,249 @@
+// Package gpx читает треки в формате GPX 1.1 и превращает их в записи
+// о тренировках с дистанцией, измеренной по координатам, а не
+// оценённой по количеству шагов.
+//
+// Дистанция между соседними точками считается по формуле гаверсинусов.
+// Время в движении — сумма промежутков между точками, на которых
+// скорость не ниже MinMovingSpeed; общее время — от первой до последней
+// точки трека вместе с остановками. Набор и сброс высоты считаются
+// с порогом ElevationThreshold, чтобы не накапливать шум высотомера.
+//
+// Средний пульс читается из расширения Garmin TrackPointExtension,
+// если оно есть.
+package gpx
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+// Параметры расчёта показателей трека.
+const (
+	// EarthRadius — средний радиус Земли, км.
+	EarthRadius = 6371.0088
+	// MinMovingSpeed — наименьшая скорость, км/ч, при которой
+	// промежуток между точками считается движением.
+	MinMovingSpeed = 1.8
+	// ElevationThreshold — наименьший перепад высоты, м, который
+	// учитывается в наборе и сбросе.
+	ElevationThreshold = 2.0
+)
+
+// Point — точка трека.
+type Point struct {
+	Lat, Lon     float64   // широта и долгота, градусы.
+	Elevation    float64   // высота над уровнем моря, м.
+	HasElevation bool      // высота указана.
+	Time         time.Time // время; нулевое, если не указано.
+	HeartRate    int       // пульс, уд/мин; 0 — не измерен.
+}
+
+// Track — трек: название, тип активности и сегменты точек. Между
+// сегментами запись трека прерывалась: расстояние и время между ними
+// не учитываются как движение.
+type Track struct {
+	Name     string
+	Type     string
+	Segments [][]Point
+}
+
+// gpxFile — разметка GPX. Элементы сопоставляются по локальному имени,
+// поэтому подходят и GPX 1.0, и GPX 1.1, и расширения с любым префиксом.
+type gpxFile struct {
+	XMLName xml.Name   `xml:"gpx"`
+	Tracks  []gpxTrack `xml:"trk"`
+}
+
+type gpxTrack struct {
+	Name     string       `xml:"name"`
+	Type     string       `xml:"type"`
+	Segments []gpxSegment `xml:"trkseg"`
+}
+
+type gpxSegment struct {
+	Points []gpxPoint `xml:"trkpt"`
+}
+
+type gpxPoint struct {
+	Lat       float64  `xml:"lat,attr"`
+	Lon       float64  `xml:"lon,attr"`
+	Elevation *float64 `xml:"ele"`
+	Time      string   `xml:"time"`
+	HeartRate int      `xml:"extensions>TrackPointExtension>hr"`
+}
+
+// Parse читает треки из документа GPX. Маршруты (rte) и отдельные
+// точки (wpt) пропускаются.
+func Parse(r io.Reader) ([]Track, error) {
+	var doc gpxFile
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, i18n.Errorf("ошибка чтения GPX: %v", err)
+	}
+	if len(doc.Tracks) == 0 {
+		return nil, i18n.Errorf("в файле GPX нет треков")
+	}
+
+	tracks := make([]Track, 0, len(doc.Tracks))
+	for _, trk := range doc.Tracks {
+		t := Track{Name: strings.TrimSpace(trk.Name), Type: strings.TrimSpace(trk.Type)}
+		for _, seg := range trk.Segments {
+			points := make([]Point, 0, len(seg.Points))
+			for _, p := range seg.Points {
+				pt, err := p.point()
+				if err != nil {
+					return nil, err
+				}
+				points = append(points, pt)
+			}
+			if len(points) > 0 {
+				t.Segments = append(t.Segments, points)
+			}
+		}
+		tracks = append(tracks, t)
+	}
+	return tracks, nil
+}
+
+func (p gpxPoint) point() (Point, error) {
+	if p.Lat < -90 || p.Lat > 90 || p.Lon < -180 || p.Lon > 180 {
+		return Point{}, i18n.Errorf("неверные координаты точки: %g, %g", p.Lat, p.Lon)
+	}
+
+	pt := Point{Lat: p.Lat, Lon: p.Lon, HeartRate: p.HeartRate}
+	if p.Elevation != nil {
+		pt.Elevation, pt.HasElevation = *p.Elevation, true
+	}
+	if s := strings.TrimSpace(p.Time); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return Point{}, i18n.Errorf("неверное время точки: %q", s)
+		}
+		pt.Time = t
+	}
+	return pt, nil
+}
+
+// Haversine возвращает расстояние по поверхности Земли между точками
+// a и b, км.
+func Haversine(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat, dLon := lat2-lat1, radians(b.Lon-a.Lon)
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLon/2), 2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// Stats — показатели трека.
+type Stats struct {
+	Start         time.Time     // время первой точки; нулевое, если времени в треке нет.
+	Distance      float64       // дистанция, км.
+	Elapsed       time.Duration // общее время от первой до последней точки.
+	Moving        time.Duration // время в движении.
+	ElevationGain float64       // набор высоты, м.
+	ElevationLoss float64       // сброс высоты, м.
+	HeartRate     int           // средний пульс по точкам, уд/мин; 0 — не измерен.
+}
+
+// Stats рассчитывает показатели трека.
+func (t Track) Stats() Stats {
+	var s Stats
+	var end time.Time
+	var hrSum, hrCount int
+
+	for _, seg := range t.Segments {
+		ref, hasRef := 0.0, false // высота, от которой считается перепад.
+		for i, p := range seg {
+			if !p.Time.IsZero() {
+				if s.Start.IsZero() || p.Time.Before(s.Start) {
+					s.Start = p.Time
+				}
+				if p.Time.After(end) {
+					end = p.Time
+				}
+			}
+			if p.HeartRate > 0 {
+				hrSum += p.HeartRate
+				hrCount++
+			}
+
+			if p.HasElevation {
+				switch diff := p.Elevation - ref; {
+				case !hasRef:
+					ref, hasRef = p.Elevation, true
+				case diff >= ElevationThreshold:
+					s.ElevationGain += diff
+					ref = p.Elevation
+				case diff <= -ElevationThreshold:
+					s.ElevationLoss -= diff
+					ref = p.Elevation
+				}
+			}
+
+			if i == 0 {
+				continue
+			}
+			prev := seg[i-1]
+			d := Haversine(prev, p)
+			s.Distance += d
+
+			dt := p.Time.Sub(prev.Time)
+			if prev.Time.IsZero() || p.Time.IsZero() || dt <= 0 {
+				continue
+			}
+			if d/dt.Hours() >= MinMovingSpeed {
+				s.Moving += dt
+			}
+		}
+	}
+
+	if !s.Start.IsZero() {
+		s.Elapsed = end.Sub(s.Start)
+	}
+	if hrCount > 0 {
+		s.HeartRate = int(math.Round(float64(hrSum) / float64(hrCount)))
+	}
+	return s
+}
+
+// Training возвращает тренировку по треку. Тип тренировки — activity,
+// а если он пуст — тип из трека; он ищется в реестре trainings.DefaultRegistry
+// без учёта регистра. Продолжительность тренировки — время в движении,
+// дистанция — измеренная по треку.
+func (t Track) Training(activity string) (trainings.Training, error) {
+	if activity == "" {
+		activity = t.Type
+	}
+	if activity == "" {
+		return trainings.Training{}, i18n.Errorf("в треке не указан тип тренировки")
+	}
+	a, ok := trainings.DefaultRegistry().LookupFold(activity)
+	if !ok {
+		return trainings.Training{}, i18n.Errorf("неизвестный тип тренировки: %s", activity)
+	}
+
+	s := t.Stats()
+	if s.Moving <= 0 {
+		return trainings.Training{}, i18n.Errorf("в треке нет точек с временем, по которым видно движение")
+	}
+
+	return trainings.Training{
+		Time:          s.Start,
+		TrainingType:  a.Name,
+		Duration:      s.Moving,
+		HeartRate:     s.HeartRate,
+		Distance:      s.Distance,
+		Elapsed:       s.Elapsed,
+		ElevationGain: s.ElevationGain,
+		ElevationLoss: s.ElevationLoss,
+	}, nil
+}
@@ -0,0 +1,145 @@
+package gpx
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/trainings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample — пробежка на север с остановкой на минуту: три отрезка
+// по 0.001° широты за 30 с и перепады высоты 1, 3 и −5 м.
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1"
+     xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <trk>
+    <name>Утренний бег</name>
+    <type>running</type>
+    <trkseg>
+      <trkpt lat="55.000" lon="37.000"><ele>100</ele><time>2024-05-01T07:00:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>140</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="55.001" lon="37.000"><ele>101</ele><time>2024-05-01T07:00:30Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+      <trkpt lat="55.002" lon="37.000"><ele>104</ele><time>2024-05-01T07:01:00Z</time></trkpt>
+      <trkpt lat="55.002" lon="37.000"><ele>104</ele><time>2024-05-01T07:02:00Z</time></trkpt>
+      <trkpt lat="55.003" lon="37.000"><ele>99</ele><time>2024-05-01T07:02:30Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>161</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions></trkpt>
+    </trkseg>
+  </trk>
+  <trk>
+    <type>cycling</type>
+    <trkseg>
+      <trkpt lat="55.000" lon="37.000"><time>2024-05-02T07:00:00Z</time></trkpt>
+      <trkpt lat="55.010" lon="37.000"><time>2024-05-02T07:02:00Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+// degreeLat — длина одного градуса меридиана, км.
+const degreeLat = EarthRadius * math.Pi / 180
+
+func TestHaversine(t *testing.T) {
+	assert.InDelta(t, degreeLat, Haversine(Point{Lat: 10, Lon: 20}, Point{Lat: 11, Lon: 20}), 1e-9)
+	assert.InDelta(t, degreeLat, Haversine(Point{Lat: 0, Lon: 179.5}, Point{Lat: 0, Lon: -179.5}), 1e-9,
+		"расстояние через линию перемены дат")
+	assert.Zero(t, Haversine(Point{Lat: 55, Lon: 37}, Point{Lat: 55, Lon: 37}))
+
+	// Москва — Санкт-Петербург, около 634 км.
+	assert.InDelta(t, 634, Haversine(Point{Lat: 55.7558, Lon: 37.6173}, Point{Lat: 59.9343, Lon: 30.3351}), 2)
+}
+
+func TestParse(t *testing.T) {
+	tracks, err := Parse(strings.NewReader(sample))
+	require.NoError(t, err)
+	require.Len(t, tracks, 2)
+
+	assert.Equal(t, "Утренний бег", tracks[0].Name)
+	assert.Equal(t, "running", tracks[0].Type)
+	require.Len(t, tracks[0].Segments, 1)
+	require.Len(t, tracks[0].Segments[0], 5)
+
+	first := tracks[0].Segments[0][0]
+	assert.Equal(t, Point{Lat: 55, Lon: 37, Elevation: 100, HasElevation: true,
+		Time: time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC), HeartRate: 140}, first)
+	assert.False(t, tracks[1].Segments[0][0].HasElevation)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"не XML", "track"},
+		{"нет треков", `<gpx version="1.1"><wpt lat="55" lon="37"/></gpx>`},
+		{"неверные координаты", `<gpx><trk><trkseg><trkpt lat="95" lon="37"/></trkseg></trk></gpx>`},
+		{"неверное время", `<gpx><trk><trkseg><trkpt lat="55" lon="37"><time>вчера</time></trkpt></trkseg></trk></gpx>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	tracks, err := Parse(strings.NewReader(sample))
+	require.NoError(t, err)
+
+	s := tracks[0].Stats()
+	assert.Equal(t, time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC), s.Start)
+	assert.InDelta(t, 0.003*degreeLat, s.Distance, 1e-9)
+	assert.Equal(t, 150*time.Second, s.Elapsed)
+	assert.Equal(t, 90*time.Second, s.Moving, "минута остановки не входит во время в движении")
+	assert.Equal(t, 4.0, s.ElevationGain, "перепад в 1 м меньше порога и накапливается")
+	assert.Equal(t, 5.0, s.ElevationLoss)
+	assert.Equal(t, 150, s.HeartRate)
+}
+
+func TestStatsSegments(t *testing.T) {
+	at := func(sec int) time.Time {
+		return time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC).Add(time.Duration(sec) * time.Second)
+	}
+	track := Track{Segments: [][]Point{
+		{{Lat: 55, Time: at(0)}, {Lat: 55.001, Time: at(30)}},
+		{{Lat: 55.01, Time: at(600)}, {Lat: 55.011, Time: at(630)}},
+	}}
+
+	s := track.Stats()
+	assert.InDelta(t, 0.002*degreeLat, s.Distance, 1e-9, "расстояние между сегментами не учитывается")
+	assert.Equal(t, time.Minute, s.Moving)
+	assert.Equal(t, 630*time.Second, s.Elapsed)
+}
+
+func TestTraining(t *testing.T) {
+	tracks, err := Parse(strings.NewReader(sample))
+	require.NoError(t, err)
+
+	tr, err := tracks[0].Training("")
+	require.NoError(t, err)
+	assert.Equal(t, trainings.Running, tr.TrainingType, "тип из трека ищется без учёта регистра")
+	assert.Equal(t, 90*time.Second, tr.Duration)
+	assert.Equal(t, 150*time.Second, tr.Elapsed)
+	assert.InDelta(t, 0.003*degreeLat, tr.Distance, 1e-9)
+	assert.Equal(t, 150, tr.HeartRate)
+	assert.Equal(t, 4.0, tr.ElevationGain)
+	assert.Equal(t, 5.0, tr.ElevationLoss)
+
+	_, err = tracks[1].Training("")
+	assert.Error(t, err, "велосипед не зарегистрирован")
+
+	tr, err = tracks[1].Training("Ходьба")
+	require.NoError(t, err)
+	assert.Equal(t, trainings.Walking, tr.TrainingType)
+
+	_, err = Track{Type: "running", Segments: [][]Point{{{Lat: 55}, {Lat: 55.001}}}}.Training("")
+	assert.Error(t, err, "без времени нельзя рассчитать время в движении")
+
+	_, err = Track{Segments: tracks[0].Segments}.Training("")
+	assert.Error(t, err, "тип не задан")
+}
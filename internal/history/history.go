@@ -0,0 +1,293 @@
+// Package history хранит обработанные записи об активности в локальном
+// файле, чтобы по ним можно было строить отчёты за длительные периоды.
+//
+// История — текстовый файл в формате JSON Lines: по одной записи
+// в строке. Новые записи дописываются в конец файла, после записи
+// вызывается fsync. Если процесс прервался посреди записи, в конце файла
+// остаётся неполная строка; Open обнаруживает её и отбрасывает.
+// Перезапись файла целиком выполняется атомарно: данные пишутся во
+// временный файл, который затем переименовывается поверх истории.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"FINAL-PROJECT-5/internal/atomicfile"
+	"FINAL-PROJECT-5/internal/daysteps"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+// Entry — запись истории: исходная строка, профиль на момент записи
+// (без журнала замеров) и рассчитанные показатели.
+type Entry struct {
+	// Activity — название типа тренировки из реестра, без учёта псевдонимов
+	// и языка вывода; для дневной активности пусто.
+	Activity string                `json:"activity,omitempty"`
+	Input    string                `json:"input,omitempty"`
+	Profile  personaldata.Personal `json:"profile"`
+	Result   report.Result         `json:"result"`
+}
+
+// FromDaySteps создаёт запись истории из разобранной записи дневной активности.
+func FromDaySteps(input string, ds daysteps.DaySteps) (Entry, error) {
+	r, err := ds.Result()
+	if err != nil {
+		return Entry{}, err
+	}
+	return Entry{Input: input, Profile: ds.Personal.AsOf(ds.Time), Result: r}, nil
+}
+
+// FromTraining создаёт запись истории из разобранной записи о тренировке.
+func FromTraining(input string, t trainings.Training) (Entry, error) {
+	r, err := t.Result()
+	if err != nil {
+		return Entry{}, err
+	}
+	activity, _ := t.Activity()
+	return Entry{Activity: activity.Name, Input: input, Profile: t.Personal.AsOf(t.Time), Result: r}, nil
+}
+
+// Query — условия отбора записей. Нулевые поля не ограничивают выборку.
+type Query struct {
+	From     time.Time // начало интервала включительно.
+	To       time.Time // конец интервала, не включается.
+	Kind     string    // report.KindDaySteps или report.KindTraining.
+	Activity string    // название типа тренировки.
+}
+
+// Match сообщает, подходит ли запись под условия. Если задан интервал,
+// записи без времени не подходят.
+func (q Query) Match(e Entry) bool {
+	if !q.From.IsZero() || !q.To.IsZero() {
+		t := e.Result.Time
+		if t.IsZero() {
+			return false
+		}
+		if !q.From.IsZero() && t.Before(q.From) {
+			return false
+		}
+		if !q.To.IsZero() && !t.Before(q.To) {
+			return false
+		}
+	}
+	if q.Kind != "" && e.Result.Kind != q.Kind {
+		return false
+	}
+	if q.Activity != "" && e.Activity != q.Activity {
+		return false
+	}
+	return true
+}
+
+// Store — история в файле. Методы Store можно вызывать из нескольких
+// горутин; одновременная работа нескольких процессов с одним файлом
+// не поддерживается.
+type Store struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Open открывает историю в файле path, создавая каталог при необходимости.
+// Неполная последняя строка, оставшаяся после сбоя, отбрасывается.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, i18n.Errorf("ошибка открытия истории: %w", err)
+	}
+
+	s := &Store{path: path}
+	if err := s.recover(); err != nil {
+		return nil, i18n.Errorf("ошибка открытия истории: %w", err)
+	}
+	return s, nil
+}
+
+// Path возвращает путь к файлу истории.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// recoverChunk — размер блока, которым recover читает файл с конца.
+const recoverChunk = 4096
+
+// recover отбрасывает неполную последнюю строку файла. Файл читается
+// с конца блоками до последнего перевода строки, так что открытие
+// истории не зависит от её размера.
+func (s *Store) recover() error {
+	f, err := os.OpenFile(s.path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, recoverChunk)
+	end := info.Size()
+	for off := end; off > 0; {
+		n := min(off, recoverChunk)
+		off -= n
+		if _, err := f.ReadAt(buf[:n], off); err != nil {
+			return err
+		}
+		i := bytes.LastIndexByte(buf[:n], '\n')
+		if i < 0 {
+			continue
+		}
+		if complete := off + int64(i) + 1; complete < end {
+			return truncate(f, complete)
+		}
+		return nil
+	}
+	if end > 0 {
+		return truncate(f, 0)
+	}
+	return nil
+}
+
+// truncate обрезает файл до size байт и сбрасывает его на диск.
+func truncate(f *os.File, size int64) error {
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// Append дописывает записи в конец истории и сбрасывает их на диск.
+func (s *Store) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return i18n.Errorf("ошибка записи истории: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return i18n.Errorf("ошибка записи истории: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return i18n.Errorf("ошибка записи истории: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return i18n.Errorf("ошибка записи истории: %w", err)
+	}
+	return nil
+}
+
+// Query возвращает записи, подходящие под q, в порядке добавления.
+func (s *Store) Query(q Query) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	err := s.scan(func(e Entry) {
+		if q.Match(e) {
+			entries = append(entries, e)
+		}
+	})
+	return entries, err
+}
+
+// Rewrite атомарно заменяет содержимое истории записями entries.
+func (s *Store) Rewrite(entries []Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.rewrite(entries)
+}
+
+// Delete удаляет записи, подходящие под q, и возвращает их количество.
+func (s *Store) Delete(q Query) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kept []Entry
+	deleted := 0
+	err := s.scan(func(e Entry) {
+		if q.Match(e) {
+			deleted++
+			return
+		}
+		kept = append(kept, e)
+	})
+	if err != nil || deleted == 0 {
+		return 0, err
+	}
+	return deleted, s.rewrite(kept)
+}
+
+func (s *Store) rewrite(entries []Entry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+
+	if err := atomicfile.WriteFile(s.path, buf.Bytes(), 0o644); err != nil {
+		return i18n.Errorf("ошибка записи истории: %w", err)
+	}
+	return nil
+}
+
+// scan читает историю и передаёт каждую запись в fn.
+func (s *Store) scan(fn func(Entry)) error {
+	f, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return i18n.Errorf("ошибка чтения истории: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			var e Entry
+			if err := json.Unmarshal(data, &e); err != nil {
+				return i18n.Errorf("повреждена запись истории в строке %d: %v", line, err)
+			}
+			fn(e)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return i18n.Errorf("ошибка чтения истории: %w", err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/daysteps"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var person = personaldata.Personal{Name: "Витя", Weight: 84.6, Height: 1.87, Age: 30, Sex: personaldata.SexMale}
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "nested", "history.jsonl"))
+	require.NoError(t, err)
+	return s
+}
+
+func sampleEntries(t *testing.T) []Entry {
+	t.Helper()
+
+	ds := daysteps.DaySteps{Personal: person}
+	require.NoError(t, ds.Parse("2024-05-01T08:00:00+03:00,6000,1h"))
+	steps, err := FromDaySteps("2024-05-01T08:00:00+03:00,6000,1h", ds)
+	require.NoError(t, err)
+
+	tr := trainings.Training{Personal: person}
+	require.NoError(t, tr.Parse("2024-05-03T19:00:00+03:00,5000,Running,30m,hr=150"))
+	run, err := FromTraining("2024-05-03T19:00:00+03:00,5000,Running,30m,hr=150", tr)
+	require.NoError(t, err)
+
+	require.NoError(t, tr.Parse("2024-06-01T10:00:00+03:00,3456,Ходьба,1h"))
+	walk, err := FromTraining("2024-06-01T10:00:00+03:00,3456,Ходьба,1h", tr)
+	require.NoError(t, err)
+
+	return []Entry{steps, run, walk}
+}
+
+func TestFromTraining(t *testing.T) {
+	entries := sampleEntries(t)
+	assert.Equal(t, "", entries[0].Activity)
+	assert.Equal(t, "Бег", entries[1].Activity, "псевдоним должен заменяться названием из реестра")
+	assert.Equal(t, person, entries[1].Profile)
+	assert.Equal(t, 150, entries[1].Result.HeartRate)
+
+	_, err := FromTraining("", trainings.Training{TrainingType: "Плавание", Steps: 1, Duration: time.Hour, Personal: person})
+	assert.Error(t, err)
+}
+
+func TestAppendQuery(t *testing.T) {
+	s := openStore(t)
+	entries := sampleEntries(t)
+
+	got, err := s.Query(Query{})
+	require.NoError(t, err)
+	assert.Empty(t, got, "новая история пуста")
+
+	require.NoError(t, s.Append(entries[0]))
+	require.NoError(t, s.Append(entries[1:]...))
+
+	msk := time.FixedZone("", 3*60*60)
+	tests := []struct {
+		name  string
+		query Query
+		want  []Entry
+	}{
+		{name: "все записи", query: Query{}, want: entries},
+		{name: "по виду", query: Query{Kind: report.KindTraining}, want: entries[1:]},
+		{name: "по типу тренировки", query: Query{Activity: "Ходьба"}, want: entries[2:]},
+		{
+			name:  "по интервалу",
+			query: Query{From: time.Date(2024, 5, 1, 0, 0, 0, 0, msk), To: time.Date(2024, 6, 1, 0, 0, 0, 0, msk)},
+			want:  entries[:2],
+		},
+		{
+			name:  "конец интервала не включается",
+			query: Query{To: time.Date(2024, 5, 1, 8, 0, 0, 0, msk)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Query(tt.query)
+			require.NoError(t, err)
+			require.Len(t, got, len(tt.want))
+			for i := range got {
+				assert.Equal(t, tt.want[i].Input, got[i].Input)
+				assert.Equal(t, tt.want[i].Activity, got[i].Activity)
+				assert.Equal(t, tt.want[i].Profile, got[i].Profile)
+				assert.True(t, tt.want[i].Result.Time.Equal(got[i].Result.Time))
+				assert.Equal(t, tt.want[i].Result.Calories, got[i].Result.Calories)
+			}
+		})
+	}
+}
+
+func TestQueryUndated(t *testing.T) {
+	s := openStore(t)
+	require.NoError(t, s.Append(Entry{Result: report.Result{Kind: report.KindDaySteps, Steps: 100}}))
+
+	got, err := s.Query(Query{})
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	got, err = s.Query(Query{From: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)})
+	require.NoError(t, err)
+	assert.Empty(t, got, "запись без времени не попадает в интервал")
+}
+
+func TestOpenRecoversTornWrite(t *testing.T) {
+	s := openStore(t)
+	entries := sampleEntries(t)
+	require.NoError(t, s.Append(entries...))
+
+	// Имитируем сбой посреди дописывания строки.
+	f, err := os.OpenFile(s.Path(), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"activity":"Бег","input":"2024-`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = s.Query(Query{})
+	assert.Error(t, err, "неполная строка без восстановления считается повреждением")
+
+	s, err = Open(s.Path())
+	require.NoError(t, err)
+	got, err := s.Query(Query{})
+	require.NoError(t, err)
+	assert.Len(t, got, len(entries))
+
+	require.NoError(t, s.Append(entries[0]))
+	got, err = s.Query(Query{})
+	require.NoError(t, err)
+	assert.Len(t, got, len(entries)+1)
+}
+
+func TestOpenRecoversLongTornLine(t *testing.T) {
+	s := openStore(t)
+	entries := sampleEntries(t)
+	require.NoError(t, s.Append(entries...))
+	info, err := os.Stat(s.Path())
+	require.NoError(t, err)
+
+	// Неполная строка длиннее блока, которым файл читается с конца.
+	f, err := os.OpenFile(s.Path(), os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"input":"` + strings.Repeat("x", 3*recoverChunk))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = Open(s.Path())
+	require.NoError(t, err)
+	after, err := os.Stat(s.Path())
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), after.Size(), "отброшена только неполная строка")
+
+	// Файл из одной неполной строки становится пустым.
+	require.NoError(t, os.WriteFile(s.Path(), []byte(`{"input":`), 0o644))
+	s, err = Open(s.Path())
+	require.NoError(t, err)
+	got, err := s.Query(Query{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestRewriteDelete(t *testing.T) {
+	s := openStore(t)
+	entries := sampleEntries(t)
+	require.NoError(t, s.Append(entries...))
+
+	n, err := s.Delete(Query{Kind: report.KindTraining})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := s.Query(Query{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, entries[0].Input, got[0].Input)
+
+	n, err = s.Delete(Query{Activity: "Бег"})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	require.NoError(t, s.Rewrite(nil))
+	got, err = s.Query(Query{})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	files, err := os.ReadDir(filepath.Dir(s.Path()))
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "временные файлы не должны оставаться")
+}
+
+func TestConcurrentAppend(t *testing.T) {
+	s := openStore(t)
+	entry := sampleEntries(t)[0]
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.Append(entry))
+		}()
+	}
+	wg.Wait()
+
+	got, err := s.Query(Query{})
+	require.NoError(t, err)
+	assert.Len(t, got, 20)
+}
@@ -0,0 +1,209 @@
+package i18n
+
+// catalog — переводы сообщений. Ключом служит сообщение на языке
+// по умолчанию (русском), значением — перевод с теми же глаголами
+// форматирования в том же порядке.
+var catalog = map[Locale]map[string]string{
+	EN: {
+		// Отчёты.
+		"Активность в течение дня":        "Daily activity",
+		"Журнал тренировок":               "Training log",
+		"Время: %s\n":                     "Time: %s\n",
+		"Имя: %s\n":                       "Name: %s\n",
+		"Вес: %.2f %s.\n":                 "Weight: %.2f %s.\n",
+		"Рост: %s.\n":                     "Height: %s.\n",
+		"Возраст: %d\n":                   "Age: %d\n",
+		"Пол: %s\n":                       "Sex: %s\n",
+		"мужской":                         "male",
+		"женский":                         "female",
+		"Пульс в покое: %d уд/мин\n":      "Resting heart rate: %d bpm\n",
+		"Максимальный пульс: %d уд/мин\n": "Max heart rate: %d bpm\n",
+		"Доля жира: %.1f%%\n":             "Body fat: %.1f%%\n",
+		"Количество шагов: %d.\nДистанция составила %.2f %s.\nВы сожгли %.2f ккал.\n":                               "Steps: %d.\nDistance: %.2f %s.\nCalories burned: %.2f kcal.\n",
+		"Суточный расход энергии: %.2f ккал.\n":                                                                     "Total daily energy expenditure: %.2f kcal.\n",
+		"Базовый обмен: %.2f ккал/сут.\n":                                                                           "Basal metabolic rate: %.2f kcal/day.\n",
+		"Расход энергии: %.2f ккал (дней с базовым обменом: %d)\n":                                                  "Energy expenditure: %.2f kcal (days with BMR: %d)\n",
+		"Тип тренировки: %s\nДлительность: %.2f ч.\nДистанция: %.2f %s.\nСкорость: %.2f %s\nСожгли калорий: %.2f\n": "Training type: %s\nDuration: %.2f h.\nDistance: %.2f %s.\nSpeed: %.2f %s\nCalories burned: %.2f\n",
+		"Средний пульс: %d уд/мин\n":                                                                                "Average heart rate: %d bpm\n",
+
+		// Итоги за период.
+		"День %s\n":     "Day %s\n",
+		"Неделя с %s\n": "Week of %s\n",
+		"Месяц %s\n":    "Month %s\n",
+		"Записей: %d\nШагов: %d\nДистанция: %.2f %s.\nАктивное время: %.2f ч.\nСожгли калорий: %.2f\n": "Records: %d\nSteps: %d\nDistance: %.2f %s.\nActive time: %.2f h.\nCalories burned: %.2f\n",
+		"  %s: записей %d, шагов %d, %.2f %s, %.2f ч., %.2f ккал\n":                                    "  %s: %d records, %d steps, %.2f %s, %.2f h., %.2f kcal\n",
+		"неизвестный период: %s":                            "unknown period: %s",
+		"у записи нет времени, её нельзя отнести к периоду": "record has no time and cannot be assigned to a period",
+
+		// Единицы измерения.
+		"км":              "km",
+		"км/ч":            "km/h",
+		"миль":            "mi",
+		"миль/ч":          "mph",
+		"кг":              "kg",
+		"фунт.":           "lb",
+		"%.2f м":          "%.2f m",
+		"%d фт %.1f дюйм": "%d ft %.1f in",
+		"мин/км":          "min/km",
+		"м":               "m",
+		"фт":              "ft",
+		"мин/миля":        "min/mi",
+
+		"Темп: %s %s\n":                   "Pace: %s %s\n",
+		"Круги:\n":                        "Laps:\n",
+		"  %d. %.2f %s за %s, темп %s\n":  "  %d. %.2f %s in %s, pace %s\n",
+		"Самый быстрый круг: %d (%s)\n":   "Fastest lap: %d (%s)\n",
+		"Самый медленный круг: %d (%s)\n": "Slowest lap: %d (%s)\n",
+		"Негативный сплит: да\n":          "Negative split: yes\n",
+		"Негативный сплит: нет\n":         "Negative split: no\n",
+
+		// Типы тренировок.
+		"Бег":    "Running",
+		"Ходьба": "Walking",
+
+		// Журнал ошибок обработки.
+		"Ошибка при обработке данных '%s': %v":                       "Error processing data '%s': %v",
+		"Ошибка при парсинге данных '%s': %v":                        "Error parsing data '%s': %v",
+		"Ошибка при получении информации об активности для '%s': %v": "Error getting activity info for '%s': %v",
+		"Ошибка при преобразовании количества шагов:":                "Error converting step count:",
+		"Ошибка при преобразовании продолжительности:":               "Error converting duration:",
+		"Ошибка: нехватка данных":                                    "Error: not enough data",
+
+		// Ошибки разбора записей.
+		"нехватка данных":                       "not enough data",
+		"количество шагов не может быть пустым": "step count must not be empty",
+		"количество шагов не должно содержать пробелов в начале или в конце":  "step count must not have leading or trailing spaces",
+		"неверный формат количества шагов":                                    "invalid step count format",
+		"количество шагов должно быть больше нуля":                            "step count must be greater than zero",
+		"неверный формат продолжительности":                                   "invalid duration format",
+		"неверный формат продолжительности: %w":                               "invalid duration format: %w",
+		"неверный формат продолжительности: %q":                               "invalid duration format: %q",
+		"слишком большая продолжительность":                                   "duration is too large",
+		"продолжительность должна быть больше нуля":                           "duration must be greater than zero",
+		"неверный формат времени записи: %q":                                  "invalid record time format: %q",
+		"неверный формат дополнительного поля: %q":                            "invalid extra field format: %q",
+		"неизвестное дополнительное поле: %q":                                 "unknown extra field: %q",
+		"неверный формат пульса: %q":                                          "invalid heart rate format: %q",
+		"пульс должен быть от %d до %d уд/мин":                                "heart rate must be between %d and %d bpm",
+		"неверная дистанция круга: %q":                                        "invalid lap distance: %q",
+		"неверное время круга: %q":                                            "invalid lap time: %q",
+		"значение должно быть больше нуля":                                    "value must be greater than zero",
+		"неизвестный тип тренировки":                                          "unknown training type",
+		"неверный формат типа тренировки":                                     "invalid training type format",
+		"неверный формат времени записи":                                      "invalid record time format",
+		"неверное дополнительное поле":                                        "invalid extra field",
+		"лишние поля в записи":                                                "too many fields in record",
+		"метка времени не должна содержать пробелов в начале или в конце":     "record time must not have leading or trailing spaces",
+		"продолжительность не должна содержать пробелов в начале или в конце": "duration must not have leading or trailing spaces",
+		"тип тренировки не должен содержать пробелов в начале или в конце":    "training type must not have leading or trailing spaces",
+		"неизвестный режим разбора: %s":                                       "unknown parsing mode: %s",
+		"неизвестный тип тренировки: %s":                                      "unknown training type: %s",
+		"для вида спорта %s нет типа тренировки: укажите тип явно (-type)":    "no training type for sport %s: set the type explicitly (-type)",
+		"недопустимая средняя скорость":                                       "invalid average speed",
+		"ошибка при расчете калорий: %v":                                      "calorie calculation error: %v",
+
+		// Реестр типов тренировок.
+		"название типа тренировки не может быть пустым":                              "training type name must not be empty",
+		"для типа тренировки %s не задан вид активности или функция расчёта калорий": "training type %s has neither an activity kind nor a calorie function",
+		"псевдоним типа тренировки %s не может быть пустым":                          "alias of training type %s must not be empty",
+		"тип тренировки %s уже зарегистрирован":                                      "training type %s is already registered",
+
+		// Профиль и расчёт калорий.
+		"вес должен быть больше нуля":                          "weight must be greater than zero",
+		"рост должен быть больше нуля":                         "height must be greater than zero",
+		"возраст должен быть больше нуля":                      "age must be greater than zero",
+		"недопустимый возраст: %d":                             "invalid age: %d",
+		"недопустимый пол: %s":                                 "invalid sex: %s",
+		"недопустимый пульс в покое: %d":                       "invalid resting heart rate: %d",
+		"недопустимый максимальный пульс: %d":                  "invalid max heart rate: %d",
+		"недопустимая доля жира: %.1f%%":                       "invalid body fat: %.1f%%",
+		"не задано время замера":                               "measurement time is not set",
+		"в замере нет ни одного параметра":                     "measurement has no values",
+		"пульс в покое должен быть меньше максимального":       "resting heart rate must be lower than max heart rate",
+		"для расчёта базового обмена нужен возраст":            "age is required to calculate basal metabolic rate",
+		"для расчёта базового обмена нужен пол":                "sex is required to calculate basal metabolic rate",
+		"для расчёта по пульсу нужен пол":                      "sex is required for heart rate based calculation",
+		"пульс должен быть больше нуля":                        "heart rate must be greater than zero",
+		"пульс %.0f уд/мин слишком низкий для расчёта калорий": "heart rate %.0f bpm is too low to calculate calories",
+		"скорость не может быть отрицательной":                 "speed must not be negative",
+		"нет значений MET для активности: %s":                  "no MET values for activity: %s",
+		"неизвестная активность: %s":                           "unknown activity: %s",
+		"неизвестная модель расчёта калорий: %d":               "unknown calorie model: %d",
+
+		// Ввод и вывод.
+		"ошибка чтения данных: %w":                                                 "error reading data: %w",
+		"ошибка записи результата: %w":                                             "error writing result: %w",
+		"неизвестный формат вывода: %s":                                            "unknown output format: %s",
+		"неподдерживаемый язык: %s":                                                "unsupported language: %s",
+		"ошибка открытия истории: %w":                                              "error opening history: %w",
+		"ошибка чтения истории: %w":                                                "error reading history: %w",
+		"ошибка записи истории: %w":                                                "error writing history: %w",
+		"повреждена запись истории в строке %d: %v":                                "corrupted history record at line %d: %v",
+		"профиль не найден: %s":                                                    "profile not found: %s",
+		"не задана команда":                                                        "no command given",
+		"неизвестная команда: %s":                                                  "unknown command: %s",
+		"не обработано записей: %d из %d":                                          "failed to process %d of %d records",
+		"неизвестный вид записей: %q (ожидается %s или %s)":                        "unknown record kind: %q (expected %s or %s)",
+		"ожидается подкоманда profile show, set, measure, list или use":            "expected subcommand profile show, set, measure, list or use",
+		"ожидается имя профиля":                                                    "profile name expected",
+		"%w; задайте профиль командой tracker profile set":                         "%w; set the profile with tracker profile set",
+		"Импортировано записей: %d, с ошибками: %d\n":                              "Imported records: %d, failed: %d\n",
+		"неверный формат даты: %q":                                                 "invalid date format: %q",
+		"не задано ни одного профиля":                                              "no profiles configured",
+		"профилей несколько, а профиль по умолчанию не задан: выберите один из %s": "several profiles exist and no default is set: choose one of %s",
+		"имя профиля не может быть пустым":                                         "profile name must not be empty",
+		"файл настроек не найден: %s":                                              "config file not found: %s",
+		"ошибка чтения настроек: %w":                                               "error reading config: %w",
+		"ошибка записи настроек: %w":                                               "error writing config: %w",
+		"ошибка в файле настроек %s: %w":                                           "error in config file %s: %w",
+		"неверный тип значения: %s":                                                "invalid value type: %s",
+		"синтаксическая ошибка в строке %d, столбце %d: %v":                        "syntax error at line %d, column %d: %v",
+		"неизвестное поле":                                                         "unknown field",
+		"неизвестный часовой пояс: %s":                                             "unknown time zone: %s",
+
+		// HTTP API.
+		"слишком большой запрос: больше %d байт":                        "request too large: more than %d bytes",
+		"неверный формат запроса: %v":                                   "invalid request format: %v",
+		"запрос должен содержать либо record, либо records":             "request must contain either record or records",
+		"слишком много записей в запросе: %d, допускается не больше %d": "too many records in request: %d, at most %d allowed",
+		"история не подключена":                                         "history is not configured",
+		"история не подключена, сохранение недоступно":                  "history is not configured, saving is unavailable",
+		"профиль не задан: передайте его в поле profile":                "no profile set: pass it in the profile field",
+		"profile.%s: %w": "profile.%s: %w",
+		"неверное значение параметра %s: %q": "invalid value of parameter %s: %q",
+		"не задан параметр %s":               "parameter %s is not set",
+		"Сервер принимает запросы на %s\n":   "Server is listening on %s\n",
+
+		// Единицы измерения во входных данных.
+		"неизвестная система единиц: %s":                                       "unknown unit system: %s",
+		"неверный формат веса: %q":                                             "invalid weight format: %q",
+		"неизвестная единица веса: %q":                                         "unknown weight unit: %q",
+		"неверный формат роста: %q":                                            "invalid height format: %q",
+		"неизвестная единица роста: %q":                                        "unknown height unit: %q",
+		"неизвестное действие для неправдоподобных записей: %s":                "unknown action for implausible records: %s",
+		"предел не может быть отрицательным":                                   "limit cannot be negative",
+		"неизвестная проверка: %s":                                             "unknown check: %s",
+		"неправдоподобный темп: %.0f шагов/мин, допускается не больше %.0f":    "implausible cadence: %.0f steps/min, at most %.0f allowed",
+		"неправдоподобная скорость: %.2f %s, допускается не больше %.2f":       "implausible speed: %.2f %s, at most %.2f allowed",
+		"неправдоподобная продолжительность: %s, допускается не больше %s":     "implausible duration: %s, at most %s allowed",
+		"Предупреждение: %s\n":                                                 "Warning: %s\n",
+		"Предупреждение для записи '%s': %v":                                   "Warning for record '%s': %v",
+		"Записей с предупреждениями: %d\n":                                     "Records with warnings: %d\n",
+		"скорость должна быть больше нуля":                                     "speed must be greater than zero",
+		"Общее время: %.2f ч.\n":                                               "Elapsed time: %.2f h.\n",
+		"Набор высоты: %.0f %s, сброс: %.0f %s\n":                              "Elevation gain: %.0f %s, loss: %.0f %s\n",
+		"ошибка чтения GPX: %v":                                                "error reading GPX: %v",
+		"в файле GPX нет треков":                                               "GPX file contains no tracks",
+		"Калории — по данным устройства; по расчёту: %.2f (разница %+.0f%%)\n": "Calories from device; estimated: %.2f (difference %+.0f%%)\n",
+		"ошибка чтения TCX: %v":                                                "error reading TCX: %v",
+		"в файле TCX нет активностей":                                          "TCX file contains no activities",
+		"отрицательные показатели круга":                                       "negative lap values",
+		"неверный формат времени в TCX: %q":                                    "invalid time format in TCX: %q",
+		"в активности не указан вид спорта":                                    "activity has no sport",
+		"в активности нет кругов с продолжительностью":                         "activity has no laps with a duration",
+		"неверные координаты точки: %g, %g":                                    "invalid point coordinates: %g, %g",
+		"неверное время точки: %q":                                             "invalid point time: %q",
+		"в треке не указан тип тренировки":                                     "track has no training type",
+		"в треке нет точек с временем, по которым видно движение":              "track has no timed points showing movement",
+	},
+}
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Locale — язык сообщений.
+type Locale string
+
+const (
+	RU Locale = "ru"
+	EN Locale = "en"
+)
+
+// DefaultLocale — язык сообщений по умолчанию. На нём же записаны
+// ключи каталога, поэтому для него перевод не нужен.
+const DefaultLocale = RU
+
+// EnvVar — переменная окружения, из которой читается язык сообщений.
+const EnvVar = "TRACKER_LANG"
+
+// Locales — поддерживаемые языки.
+var Locales = []Locale{RU, EN}
+
+// current хранит текущий язык; пока SetLocale не вызывался, он пуст
+// и используется DefaultLocale.
+var current atomic.Value
+
+// SetLocale устанавливает язык сообщений для всего приложения.
+func SetLocale(l Locale) {
+	current.Store(l)
+}
+
+// Current возвращает текущий язык сообщений.
+func Current() Locale {
+	if l, ok := current.Load().(Locale); ok {
+		return l
+	}
+	return DefaultLocale
+}
+
+// ParseLocale распознаёт язык по названию: "en", "EN", "en_US.UTF-8", "ru-RU".
+func ParseLocale(s string) (Locale, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(name, "_-."); i >= 0 {
+		name = name[:i]
+	}
+
+	for _, l := range Locales {
+		if Locale(name) == l {
+			return l, nil
+		}
+	}
+	return "", Errorf("неподдерживаемый язык: %s", s)
+}
+
+// FromEnv возвращает язык из переменной окружения EnvVar или язык
+// по умолчанию, если она не задана или содержит неизвестный язык.
+func FromEnv() Locale {
+	if l, err := ParseLocale(os.Getenv(EnvVar)); err == nil {
+		return l
+	}
+	return DefaultLocale
+}
+
+// T переводит сообщение на текущий язык. Если переданы аргументы,
+// сообщение используется как формат fmt.Sprintf.
+func T(msg string, args ...any) string {
+	return In(Current(), msg, args...)
+}
+
+// In переводит сообщение на язык l.
+func In(l Locale, msg string, args ...any) string {
+	if translated, ok := catalog[l][msg]; ok {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Errorf создаёт ошибку с сообщением на текущем языке. Как и в fmt.Errorf,
+// глагол %w оборачивает ошибку.
+func Errorf(format string, args ...any) error {
+	return fmt.Errorf(T(format), args...)
+}
+
+// Translations возвращает все сообщения, для которых есть перевод на язык l.
+func Translations(l Locale) map[string]string {
+	out := make(map[string]string, len(catalog[l]))
+	for k, v := range catalog[l] {
+		out[k] = v
+	}
+	return out
+}
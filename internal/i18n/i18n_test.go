@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Locale
+		wantErr bool
+	}{
+		{input: "ru", want: RU},
+		{input: "EN", want: EN},
+		{input: "en_US.UTF-8", want: EN},
+		{input: "ru-RU", want: RU},
+		{input: "de", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseLocale(tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvVar, "en")
+	assert.Equal(t, EN, FromEnv())
+
+	t.Setenv(EnvVar, "klingon")
+	assert.Equal(t, DefaultLocale, FromEnv())
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLocale(Current())
+
+	SetLocale(RU)
+	assert.Equal(t, "Имя: Иван\n", T("Имя: %s\n", "Иван"))
+	assert.Equal(t, "нет перевода", T("нет перевода"), "сообщение без перевода выводится как есть")
+
+	SetLocale(EN)
+	assert.Equal(t, "Name: Ivan\n", T("Имя: %s\n", "Ivan"))
+	assert.Equal(t, "Running", T("Бег"))
+	assert.Equal(t, "Бег", In(RU, "Бег"))
+
+	base := errors.New("EOF")
+	err := Errorf("ошибка чтения данных: %w", base)
+	assert.Equal(t, "error reading data: EOF", err.Error())
+	assert.ErrorIs(t, err, base)
+}
+
+// callPattern находит сообщения, передаваемые в T, In и Errorf строковым литералом.
+var callPattern = regexp.MustCompile(`\b(?:i18n\.)?(?:T|Errorf|In\([^,]+,)\s*\(?("(?:[^"\\]|\\.)*")`)
+
+// TestCatalogComplete проверяет, что у каждого сообщения в исходниках есть перевод
+// на все поддерживаемые языки.
+func TestCatalogComplete(t *testing.T) {
+	root := filepath.Join("..", "..")
+	messages := map[string]string{}
+
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != root {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range callPattern.FindAllStringSubmatch(string(data), -1) {
+			msg, err := strconv.Unquote(m[1])
+			if err != nil {
+				return err
+			}
+			messages[msg] = path
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, messages)
+
+	for _, l := range Locales {
+		if l == DefaultLocale {
+			continue
+		}
+		translations := Translations(l)
+		for msg, path := range messages {
+			assert.Contains(t, translations, msg, "нет перевода на %s для сообщения из %s", l, path)
+		}
+	}
+}
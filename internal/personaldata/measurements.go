@@ -0,0 +1,104 @@
+package personaldata
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// Measurement — замер параметров тела. Нулевое значение параметра
+// означает, что в этом замере он не измерялся.
+type Measurement struct {
+	Time    time.Time `json:"time"`
+	Weight  float64   `json:"weight_kg,omitempty"`
+	Height  float64   `json:"height_m,omitempty"`
+	BodyFat float64   `json:"body_fat_pct,omitempty"` // доля жира, %.
+}
+
+// AsOf возвращает профиль с параметрами тела на момент t. Каждый параметр
+// берётся из журнала замеров: между двумя замерами значение интерполируется
+// линейно по времени, до первого и после последнего замера берётся
+// ближайший замер. Если параметр ни разу не измерялся или t нулевое,
+// остаётся значение из профиля. Журнал замеров в результате не сохраняется.
+func (p Personal) AsOf(t time.Time) Personal {
+	measurements := p.Measurements
+	p.Measurements = nil
+	if t.IsZero() || len(measurements) == 0 {
+		return p
+	}
+
+	sorted := make([]Measurement, len(measurements))
+	copy(sorted, measurements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	if v, ok := interpolate(sorted, t, func(m Measurement) float64 { return m.Weight }); ok {
+		p.Weight = v
+	}
+	if v, ok := interpolate(sorted, t, func(m Measurement) float64 { return m.Height }); ok {
+		p.Height = v
+	}
+	if v, ok := interpolate(sorted, t, func(m Measurement) float64 { return m.BodyFat }); ok {
+		p.BodyFat = v
+	}
+	return p
+}
+
+// interpolate возвращает значение параметра value на момент t по замерам,
+// отсортированным по времени. Замеры без параметра пропускаются.
+func interpolate(measurements []Measurement, t time.Time, value func(Measurement) float64) (float64, bool) {
+	var prev *Measurement
+	for i := range measurements {
+		m := &measurements[i]
+		if value(*m) <= 0 {
+			continue
+		}
+		if !m.Time.After(t) {
+			prev = m
+			continue
+		}
+
+		// m — первый замер после t.
+		if prev == nil {
+			return value(*m), true
+		}
+		span := m.Time.Sub(prev.Time)
+		if span <= 0 {
+			return value(*m), true
+		}
+		frac := float64(t.Sub(prev.Time)) / float64(span)
+		return value(*prev) + (value(*m)-value(*prev))*frac, true
+	}
+
+	if prev == nil {
+		return 0, false
+	}
+	return value(*prev), true
+}
+
+// validateMeasurements проверяет журнал замеров. Поле в ошибке
+// указывается с номером замера, например "measurements[2].weight_kg".
+func (p Personal) validateMeasurements() error {
+	for i, m := range p.Measurements {
+		field := func(name string) string {
+			return fmt.Sprintf("measurements[%d].%s", i, name)
+		}
+
+		switch {
+		case m.Time.IsZero():
+			return fieldError(field("time"), i18n.Errorf("не задано время замера"))
+		case !nonNegative(m.Weight):
+			return fieldError(field("weight_kg"), i18n.Errorf("вес должен быть больше нуля"))
+		case !nonNegative(m.Height):
+			return fieldError(field("height_m"), i18n.Errorf("рост должен быть больше нуля"))
+		case !(m.BodyFat >= 0 && m.BodyFat < 100):
+			return fieldError(field("body_fat_pct"), i18n.Errorf("недопустимая доля жира: %.1f%%", m.BodyFat))
+		case m.Weight == 0 && m.Height == 0 && m.BodyFat == 0:
+			return fieldError(fmt.Sprintf("measurements[%d]", i), i18n.Errorf("в замере нет ни одного параметра"))
+		}
+	}
+	return nil
+}
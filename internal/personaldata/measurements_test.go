@@ -0,0 +1,92 @@
+package personaldata
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(d int) time.Time {
+	return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAsOf(t *testing.T) {
+	p := Personal{
+		Name:   "Витя",
+		Weight: 80,
+		Height: 1.87,
+		Measurements: []Measurement{
+			// Замеры нарочно не упорядочены по времени.
+			{Time: day(11), Weight: 88, BodyFat: 20},
+			{Time: day(1), Weight: 90},
+			{Time: day(21), Weight: 86, Height: 1.88},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		at          time.Time
+		wantWeight  float64
+		wantHeight  float64
+		wantBodyFat float64
+	}{
+		{name: "время неизвестно", at: time.Time{}, wantWeight: 80, wantHeight: 1.87},
+		{name: "до первого замера", at: day(1).Add(-time.Hour), wantWeight: 90, wantHeight: 1.88, wantBodyFat: 20},
+		{name: "точно в момент замера", at: day(11), wantWeight: 88, wantHeight: 1.88, wantBodyFat: 20},
+		{name: "между замерами", at: day(6), wantWeight: 89, wantHeight: 1.88, wantBodyFat: 20},
+		{name: "между замерами с разными параметрами", at: day(16), wantWeight: 87, wantHeight: 1.88, wantBodyFat: 20},
+		{name: "после последнего замера", at: day(30), wantWeight: 86, wantHeight: 1.88, wantBodyFat: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := p.AsOf(tt.at)
+			assert.InDelta(t, tt.wantWeight, got.Weight, 1e-9)
+			assert.InDelta(t, tt.wantHeight, got.Height, 1e-9)
+			assert.InDelta(t, tt.wantBodyFat, got.BodyFat, 1e-9)
+			assert.Equal(t, "Витя", got.Name)
+			assert.Nil(t, got.Measurements, "журнал замеров не копируется")
+		})
+	}
+
+	assert.Equal(t, time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC), p.Measurements[0].Time, "исходный журнал не должен меняться")
+}
+
+func TestAsOfWithoutMeasurements(t *testing.T) {
+	p := Personal{Name: "Иван", Weight: 75, Height: 1.75}
+	assert.Equal(t, p, p.AsOf(day(1)))
+}
+
+func TestValidateMeasurements(t *testing.T) {
+	base := Personal{Weight: 75, Height: 1.75}
+
+	tests := []struct {
+		name        string
+		measurement Measurement
+		wantField   string
+	}{
+		{name: "без времени", measurement: Measurement{Weight: 75}, wantField: "measurements[0].time"},
+		{name: "отрицательный вес", measurement: Measurement{Time: day(1), Weight: -1}, wantField: "measurements[0].weight_kg"},
+		{name: "отрицательный рост", measurement: Measurement{Time: day(1), Height: -1}, wantField: "measurements[0].height_m"},
+		{name: "вес NaN", measurement: Measurement{Time: day(1), Weight: math.NaN()}, wantField: "measurements[0].weight_kg"},
+		{name: "бесконечный рост", measurement: Measurement{Time: day(1), Height: math.Inf(1)}, wantField: "measurements[0].height_m"},
+		{name: "доля жира 100%", measurement: Measurement{Time: day(1), BodyFat: 100}, wantField: "measurements[0].body_fat_pct"},
+		{name: "пустой замер", measurement: Measurement{Time: day(1)}, wantField: "measurements[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := base
+			p.Measurements = []Measurement{tt.measurement}
+			var fe *FieldError
+			require.ErrorAs(t, p.Validate(), &fe)
+			assert.Equal(t, tt.wantField, fe.Field)
+		})
+	}
+
+	base.Measurements = []Measurement{{Time: day(1), Weight: 76}, {Time: day(2), BodyFat: 18.5}}
+	assert.NoError(t, base.Validate())
+}
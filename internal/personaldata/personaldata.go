@@ -1,16 +1,207 @@
 package personaldata
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/units"
+)
+
+// Sex — пол пользователя. Пустое значение означает, что пол не указан.
+type Sex string
+
+const (
+	SexMale   Sex = "male"
+	SexFemale Sex = "female"
+)
+
+func (s Sex) title() string {
+	switch s {
+	case SexMale:
+		return i18n.T("мужской")
+	case SexFemale:
+		return i18n.T("женский")
+	}
+	return string(s)
+}
+
+// ParseSex распознаёт пол по названию без учёта регистра: "male"/"female",
+// "m"/"f", "мужской"/"женский", "м"/"ж".
+func ParseSex(s string) (Sex, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "male", "m", "мужской", "м":
+		return SexMale, nil
+	case "female", "f", "женский", "ж":
+		return SexFemale, nil
+	}
+	return "", i18n.Errorf("недопустимый пол: %s", s)
+}
 
 type Personal struct {
-	Name   string
-	Weight float64
-	Height float64
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight_kg"`
+	Height float64 `json:"height_m"`
+
+	// Необязательные поля; нулевое значение означает, что параметр не указан.
+	Age              int     `json:"age,omitempty"` // возраст, полных лет.
+	Sex              Sex     `json:"sex,omitempty"`
+	RestingHeartRate int     `json:"resting_heart_rate,omitempty"` // пульс в покое, уд/мин.
+	MaxHeartRate     int     `json:"max_heart_rate,omitempty"`     // максимальный пульс, уд/мин.
+	BodyFat          float64 `json:"body_fat_pct,omitempty"`       // доля жира, %.
+
+	// Measurements — журнал замеров параметров тела; см. AsOf.
+	Measurements []Measurement `json:"measurements,omitempty"`
 }
 
 func (p Personal) Print() {
-	fmt.Printf("Имя: %s\n", p.Name)
-	fmt.Printf("Вес: %.2f кг.\n", p.Weight)
-	fmt.Printf("Рост: %.2f м.\n", p.Height)
-	fmt.Println()
+	p.Fprint(os.Stdout)
+}
+
+// Fprint выводит данные профиля в w.
+func (p Personal) Fprint(w io.Writer) {
+	fmt.Fprint(w, i18n.T("Имя: %s\n", p.Name))
+	weight, weightUnit := units.Current().Weight(p.Weight)
+	fmt.Fprint(w, i18n.T("Вес: %.2f %s.\n", weight, weightUnit))
+	fmt.Fprint(w, i18n.T("Рост: %s.\n", units.Current().FormatHeight(p.Height)))
+	if p.Age > 0 {
+		fmt.Fprint(w, i18n.T("Возраст: %d\n", p.Age))
+	}
+	if p.Sex != "" {
+		fmt.Fprint(w, i18n.T("Пол: %s\n", p.Sex.title()))
+	}
+	if p.RestingHeartRate > 0 {
+		fmt.Fprint(w, i18n.T("Пульс в покое: %d уд/мин\n", p.RestingHeartRate))
+	}
+	if p.MaxHeartRate > 0 {
+		fmt.Fprint(w, i18n.T("Максимальный пульс: %d уд/мин\n", p.MaxHeartRate))
+	}
+	if p.BodyFat > 0 {
+		fmt.Fprint(w, i18n.T("Доля жира: %.1f%%\n", p.BodyFat))
+	}
+	fmt.Fprintln(w)
+}
+
+// FieldError — ошибка в поле профиля. Field — имя поля в JSON,
+// например "weight_kg".
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func fieldError(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
+
+// Validate проверяет, что данные профиля допустимы. Ошибка имеет тип
+// *FieldError и указывает на поле с недопустимым значением.
+func (p Personal) Validate() error {
+	if !positive(p.Weight) {
+		return fieldError("weight_kg", i18n.Errorf("вес должен быть больше нуля"))
+	}
+	if !positive(p.Height) {
+		return fieldError("height_m", i18n.Errorf("рост должен быть больше нуля"))
+	}
+	if p.Age < 0 || p.Age > 150 {
+		return fieldError("age", i18n.Errorf("недопустимый возраст: %d", p.Age))
+	}
+	if p.Sex != "" && p.Sex != SexMale && p.Sex != SexFemale {
+		return fieldError("sex", i18n.Errorf("недопустимый пол: %s", p.Sex))
+	}
+	if p.RestingHeartRate < 0 || p.RestingHeartRate > 250 {
+		return fieldError("resting_heart_rate", i18n.Errorf("недопустимый пульс в покое: %d", p.RestingHeartRate))
+	}
+	if p.MaxHeartRate < 0 || p.MaxHeartRate > 250 {
+		return fieldError("max_heart_rate", i18n.Errorf("недопустимый максимальный пульс: %d", p.MaxHeartRate))
+	}
+	if p.RestingHeartRate > 0 && p.MaxHeartRate > 0 && p.RestingHeartRate >= p.MaxHeartRate {
+		return fieldError("resting_heart_rate", i18n.Errorf("пульс в покое должен быть меньше максимального"))
+	}
+	if !(p.BodyFat >= 0 && p.BodyFat < 100) {
+		return fieldError("body_fat_pct", i18n.Errorf("недопустимая доля жира: %.1f%%", p.BodyFat))
+	}
+	return p.validateMeasurements()
+}
+
+// positive сообщает, что v — конечное число больше нуля. NaN
+// не проходит ни одно сравнение, поэтому проверка записана через «>».
+func positive(v float64) bool {
+	return v > 0 && !math.IsInf(v, 1)
+}
+
+// nonNegative сообщает, что v — конечное неотрицательное число.
+func nonNegative(v float64) bool {
+	return v >= 0 && !math.IsInf(v, 1)
+}
+
+// BMI возвращает индекс массы тела: вес (кг) / рост² (м).
+func (p Personal) BMI() (float64, error) {
+	if !positive(p.Weight) {
+		return 0, i18n.Errorf("вес должен быть больше нуля")
+	}
+	if !positive(p.Height) {
+		return 0, i18n.Errorf("рост должен быть больше нуля")
+	}
+	return p.Weight / (p.Height * p.Height), nil
+}
+
+// BMRMifflinStJeor возвращает базовый обмен веществ (ккал/сутки)
+// по формуле Миффлина — Сан Жеора.
+func (p Personal) BMRMifflinStJeor() (float64, error) {
+	if err := p.checkBMR(); err != nil {
+		return 0, err
+	}
+
+	bmr := 10*p.Weight + 6.25*p.heightCm() - 5*float64(p.Age)
+	if p.Sex == SexMale {
+		return bmr + 5, nil
+	}
+	return bmr - 161, nil
+}
+
+// BMRHarrisBenedict возвращает базовый обмен веществ (ккал/сутки)
+// по пересмотренной формуле Харриса — Бенедикта (Roza, Shizgal, 1984).
+func (p Personal) BMRHarrisBenedict() (float64, error) {
+	if err := p.checkBMR(); err != nil {
+		return 0, err
+	}
+
+	age := float64(p.Age)
+	if p.Sex == SexMale {
+		return 88.362 + 13.397*p.Weight + 4.799*p.heightCm() - 5.677*age, nil
+	}
+	return 447.593 + 9.247*p.Weight + 3.098*p.heightCm() - 4.330*age, nil
+}
+
+// HasBMR сообщает, достаточно ли данных профиля для расчёта базового обмена.
+func (p Personal) HasBMR() bool {
+	return p.checkBMR() == nil
+}
+
+func (p Personal) checkBMR() error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	if p.Age <= 0 {
+		return i18n.Errorf("для расчёта базового обмена нужен возраст")
+	}
+	if p.Sex == "" {
+		return i18n.Errorf("для расчёта базового обмена нужен пол")
+	}
+	return nil
+}
+
+func (p Personal) heightCm() float64 {
+	return p.Height * 100
 }
@@ -2,6 +2,7 @@ package personaldata
 
 import (
 	"bytes"
+	"math"
 	"os"
 	"testing"
 
@@ -51,6 +52,19 @@ func TestPrint(t *testing.T) {
 			},
 			want: "Имя: Алексей\nВес: 100.00 кг.\nРост: 2.00 м.\n\n",
 		},
+		{
+			name: "необязательные поля",
+			personal: Personal{
+				Name:             "Анна",
+				Weight:           60.0,
+				Height:           1.65,
+				Age:              40,
+				Sex:              SexFemale,
+				RestingHeartRate: 60,
+				MaxHeartRate:     180,
+			},
+			want: "Имя: Анна\nВес: 60.00 кг.\nРост: 1.65 м.\nВозраст: 40\nПол: женский\nПульс в покое: 60 уд/мин\nМаксимальный пульс: 180 уд/мин\n\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -79,3 +93,117 @@ func TestPrint(t *testing.T) {
 		})
 	}
 }
+
+func TestFprint(t *testing.T) {
+	var buf bytes.Buffer
+	Personal{Name: "Иван", Weight: 75, Height: 1.75}.Fprint(&buf)
+	assert.Equal(t, "Имя: Иван\nВес: 75.00 кг.\nРост: 1.75 м.\n\n", buf.String())
+}
+
+func TestParseSex(t *testing.T) {
+	for input, want := range map[string]Sex{"male": SexMale, " F ": SexFemale, "Мужской": SexMale, "ж": SexFemale} {
+		got, err := ParseSex(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParseSex("x")
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	valid := Personal{Weight: 75, Height: 1.75, Age: 30, Sex: SexMale, RestingHeartRate: 55, MaxHeartRate: 190}
+	require.NoError(t, valid.Validate())
+	require.NoError(t, Personal{Weight: 75, Height: 1.75}.Validate(), "необязательные поля можно не заполнять")
+
+	tests := []struct {
+		name      string
+		modify    func(p *Personal)
+		wantField string
+	}{
+		{name: "нулевой вес", modify: func(p *Personal) { p.Weight = 0 }, wantField: "weight_kg"},
+		{name: "отрицательный рост", modify: func(p *Personal) { p.Height = -1 }, wantField: "height_m"},
+		{name: "вес NaN", modify: func(p *Personal) { p.Weight = math.NaN() }, wantField: "weight_kg"},
+		{name: "бесконечный рост", modify: func(p *Personal) { p.Height = math.Inf(1) }, wantField: "height_m"},
+		{name: "доля жира NaN", modify: func(p *Personal) { p.BodyFat = math.NaN() }, wantField: "body_fat_pct"},
+		{name: "отрицательный возраст", modify: func(p *Personal) { p.Age = -1 }, wantField: "age"},
+		{name: "неизвестный пол", modify: func(p *Personal) { p.Sex = "x" }, wantField: "sex"},
+		{name: "пульс в покое больше максимального", modify: func(p *Personal) { p.RestingHeartRate = 200 }, wantField: "resting_heart_rate"},
+		{name: "слишком большой пульс", modify: func(p *Personal) { p.MaxHeartRate = 300 }, wantField: "max_heart_rate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := valid
+			tt.modify(&p)
+			err := p.Validate()
+			var fe *FieldError
+			require.ErrorAs(t, err, &fe)
+			assert.Equal(t, tt.wantField, fe.Field)
+		})
+	}
+}
+
+func TestBMI(t *testing.T) {
+	got, err := Personal{Weight: 80, Height: 2}.BMI()
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, got)
+
+	_, err = Personal{Weight: 80}.BMI()
+	assert.Error(t, err)
+
+	_, err = Personal{Weight: math.NaN(), Height: 2}.BMI()
+	assert.Error(t, err)
+
+	_, err = Personal{Weight: 80, Height: math.Inf(1)}.BMI()
+	assert.Error(t, err)
+}
+
+func TestBMR(t *testing.T) {
+	tests := []struct {
+		name        string
+		personal    Personal
+		wantMifflin float64
+		wantHarris  float64
+		wantErr     bool
+	}{
+		{
+			name:        "мужчина",
+			personal:    Personal{Weight: 75, Height: 1.75, Age: 30, Sex: SexMale},
+			wantMifflin: 1698.75,
+			wantHarris:  1762.652,
+		},
+		{
+			name:        "женщина",
+			personal:    Personal{Weight: 60, Height: 1.65, Age: 40, Sex: SexFemale},
+			wantMifflin: 1270.25,
+			wantHarris:  1340.383,
+		},
+		{
+			name:     "не указан возраст",
+			personal: Personal{Weight: 75, Height: 1.75, Sex: SexMale},
+			wantErr:  true,
+		},
+		{
+			name:     "не указан пол",
+			personal: Personal{Weight: 75, Height: 1.75, Age: 30},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mifflin, err := tt.personal.BMRMifflinStJeor()
+			harris, herr := tt.personal.BMRHarrisBenedict()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Error(t, herr)
+				assert.False(t, tt.personal.HasBMR())
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, herr)
+			assert.InDelta(t, tt.wantMifflin, mifflin, 1e-9)
+			assert.InDelta(t, tt.wantHarris, harris, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// Package plausibility проверяет правдоподобие записей об активности:
+// темп шагов, среднюю скорость для вида активности и продолжительность.
+//
+// Каждое нарушение либо отмечается предупреждением (Flag) — запись
+// принимается, а предупреждение сохраняется вместе с ней, — либо
+// отклоняет запись (Reject). Так испорченные выгрузки с устройств
+// не попадают в статистику, а сомнительные записи остаются видны.
+package plausibility
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/spentenergy"
+	"FINAL-PROJECT-5/internal/units"
+)
+
+// Check — вид проверки.
+type Check string
+
+const (
+	Cadence  Check = "cadence"  // темп шагов, шагов в минуту.
+	Speed    Check = "speed"    // средняя скорость, км/ч.
+	Duration Check = "duration" // продолжительность записи.
+)
+
+// Checks — все виды проверок.
+var Checks = []Check{Cadence, Speed, Duration}
+
+// Action — что делать с записью, не прошедшей проверку.
+type Action string
+
+const (
+	Off    Action = "off"    // не проверять.
+	Flag   Action = "flag"   // принять запись с предупреждением.
+	Reject Action = "reject" // отклонить запись.
+)
+
+// ParseAction распознаёт действие по названию без учёта регистра.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case Off, Flag, Reject:
+		return a, nil
+	}
+	return "", i18n.Errorf("неизвестное действие для неправдоподобных записей: %s", s)
+}
+
+// Rules — правила проверки. Нулевой предел означает, что величина
+// не ограничена.
+type Rules struct {
+	// MaxCadence — наибольший средний темп, шагов в минуту.
+	MaxCadence float64 `json:"max_cadence_spm,omitempty"`
+	// MaxSpeed — наибольшая средняя скорость, км/ч, по названию типа
+	// тренировки из реестра (например "Бег") или по виду активности
+	// spentenergy ("walking", "running"); название важнее вида.
+	// Дневная активность проверяется как spentenergy.Walking.
+	MaxSpeed map[string]float64 `json:"max_speed_kmh,omitempty"`
+	// MaxDuration — наибольшая продолжительность одной записи.
+	MaxDuration time.Duration `json:"-"`
+	// Actions — действие для каждого вида проверки; по умолчанию Reject.
+	Actions map[Check]Action `json:"actions,omitempty"`
+}
+
+// DefaultRules возвращает правила по умолчанию: темп до 250 шагов
+// в минуту, ходьба до 10 км/ч, бег до 30 км/ч и запись не длиннее суток.
+// Превышение скорости только отмечается: чаще всего это ошибка в типе
+// тренировки, а не в данных; остальные нарушения отклоняют запись.
+func DefaultRules() Rules {
+	return Rules{
+		MaxCadence:  250,
+		MaxSpeed:    map[string]float64{spentenergy.Walking: 10, spentenergy.Running: 30},
+		MaxDuration: 24 * time.Hour,
+		Actions:     map[Check]Action{Cadence: Reject, Speed: Flag, Duration: Reject},
+	}
+}
+
+// Merge возвращает правила r, в которых заданные в o пределы и действия
+// заменяют прежние. Так правила из файла настроек дополняют правила
+// по умолчанию.
+func (r Rules) Merge(o Rules) Rules {
+	out := Rules{MaxCadence: r.MaxCadence, MaxDuration: r.MaxDuration}
+	if o.MaxCadence != 0 {
+		out.MaxCadence = o.MaxCadence
+	}
+	if o.MaxDuration != 0 {
+		out.MaxDuration = o.MaxDuration
+	}
+	out.MaxSpeed = mergeMaps(r.MaxSpeed, o.MaxSpeed)
+	out.Actions = mergeMaps(r.Actions, o.Actions)
+	return out
+}
+
+func mergeMaps[K comparable, V any](a, b map[K]V) map[K]V {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+	out := make(map[K]V, len(a)+len(b))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
+
+// WithAction возвращает правила r, в которых для всех проверок задано
+// действие a.
+func (r Rules) WithAction(a Action) Rules {
+	out := r.Merge(Rules{})
+	out.Actions = make(map[Check]Action, len(Checks))
+	for _, c := range Checks {
+		out.Actions[c] = a
+	}
+	return out
+}
+
+// Validate проверяет, что пределы неотрицательны, а действия известны.
+func (r Rules) Validate() error {
+	if r.MaxCadence < 0 {
+		return &FieldError{Field: "max_cadence_spm", Err: i18n.Errorf("предел не может быть отрицательным")}
+	}
+	for name, v := range r.MaxSpeed {
+		if v < 0 {
+			return &FieldError{Field: "max_speed_kmh." + name, Err: i18n.Errorf("предел не может быть отрицательным")}
+		}
+	}
+	if r.MaxDuration < 0 {
+		return &FieldError{Field: "max_duration", Err: i18n.Errorf("предел не может быть отрицательным")}
+	}
+	for c, a := range r.Actions {
+		if c != Cadence && c != Speed && c != Duration {
+			return &FieldError{Field: "actions." + string(c), Err: i18n.Errorf("неизвестная проверка: %s", c)}
+		}
+		if _, err := ParseAction(string(a)); err != nil {
+			return &FieldError{Field: "actions." + string(c), Err: err}
+		}
+	}
+	return nil
+}
+
+// FieldError — ошибка в поле правил. Field — путь к полю в JSON,
+// например "max_speed_kmh.running".
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// jsonRules — представление Rules в JSON: продолжительность
+// записывается строкой в любом из форматов record.ParseDuration.
+type jsonRules struct {
+	MaxCadence  float64            `json:"max_cadence_spm,omitempty"`
+	MaxSpeed    map[string]float64 `json:"max_speed_kmh,omitempty"`
+	MaxDuration string             `json:"max_duration,omitempty"`
+	Actions     map[Check]Action   `json:"actions,omitempty"`
+}
+
+func (r Rules) MarshalJSON() ([]byte, error) {
+	jr := jsonRules{MaxCadence: r.MaxCadence, MaxSpeed: r.MaxSpeed, Actions: r.Actions}
+	if r.MaxDuration != 0 {
+		jr.MaxDuration = r.MaxDuration.String()
+	}
+	return json.Marshal(jr)
+}
+
+func (r *Rules) UnmarshalJSON(data []byte) error {
+	var jr jsonRules
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&jr); err != nil {
+		return err
+	}
+
+	*r = Rules{MaxCadence: jr.MaxCadence, MaxSpeed: jr.MaxSpeed, Actions: jr.Actions}
+	if jr.MaxDuration != "" {
+		d, err := record.ParseDuration(jr.MaxDuration)
+		if err != nil {
+			return &FieldError{Field: "max_duration", Err: err}
+		}
+		r.MaxDuration = d
+	}
+	return nil
+}
+
+// Record — показатели записи, которые проверяются.
+type Record struct {
+	Activity string        // название типа тренировки; для дневной активности пусто.
+	Kind     string        // вид активности spentenergy; пусто, если неизвестен.
+	Steps    int           // количество шагов.
+	Duration time.Duration // продолжительность.
+	Speed    float64       // средняя скорость, км/ч.
+}
+
+// ErrImplausible сопоставляется через errors.Is с любым нарушением.
+var ErrImplausible = errors.New("implausible record")
+
+// Violation — нарушение правила. Value и Limit записываются в единицах
+// проверки: шаги в минуту, км/ч или часы.
+type Violation struct {
+	Check Check   `json:"check"`
+	Value float64 `json:"value"`
+	Limit float64 `json:"limit"`
+}
+
+func (v Violation) Error() string {
+	switch v.Check {
+	case Cadence:
+		return i18n.T("неправдоподобный темп: %.0f шагов/мин, допускается не больше %.0f", v.Value, v.Limit)
+	case Speed:
+		sys := units.Current()
+		value, unit := sys.Speed(v.Value)
+		limit, _ := sys.Speed(v.Limit)
+		return i18n.T("неправдоподобная скорость: %.2f %s, допускается не больше %.2f", value, unit, limit)
+	}
+	return i18n.T("неправдоподобная продолжительность: %s, допускается не больше %s", hours(v.Value), hours(v.Limit))
+}
+
+func (v Violation) Is(target error) bool {
+	return target == ErrImplausible
+}
+
+func hours(h float64) time.Duration {
+	return time.Duration(h * float64(time.Hour)).Round(time.Second)
+}
+
+// Check проверяет запись. Нарушения с действием Flag возвращаются
+// предупреждениями, первое нарушение с действием Reject — ошибкой типа
+// Violation. Если r равно nil, запись не проверяется.
+func (r *Rules) Check(rec Record) ([]Violation, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	var found []Violation
+	if minutes := rec.Duration.Minutes(); r.MaxCadence > 0 && minutes > 0 {
+		if cadence := float64(rec.Steps) / minutes; cadence > r.MaxCadence {
+			found = append(found, Violation{Check: Cadence, Value: cadence, Limit: r.MaxCadence})
+		}
+	}
+	if limit := r.maxSpeed(rec); limit > 0 && rec.Speed > limit {
+		found = append(found, Violation{Check: Speed, Value: rec.Speed, Limit: limit})
+	}
+	if r.MaxDuration > 0 && rec.Duration > r.MaxDuration {
+		found = append(found, Violation{Check: Duration, Value: rec.Duration.Hours(), Limit: r.MaxDuration.Hours()})
+	}
+
+	var warnings []Violation
+	for _, v := range found {
+		switch r.action(v.Check) {
+		case Reject:
+			return nil, v
+		case Flag:
+			warnings = append(warnings, v)
+		}
+	}
+	return warnings, nil
+}
+
+func (r *Rules) maxSpeed(rec Record) float64 {
+	if limit, ok := r.MaxSpeed[rec.Activity]; ok && rec.Activity != "" {
+		return limit
+	}
+	if rec.Kind != "" {
+		return r.MaxSpeed[rec.Kind]
+	}
+	return 0
+}
+
+func (r *Rules) action(c Check) Action {
+	if a, ok := r.Actions[c]; ok {
+		return a
+	}
+	return Reject
+}
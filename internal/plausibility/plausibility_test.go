@@ -0,0 +1,179 @@
+package plausibility
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/spentenergy"
+	"FINAL-PROJECT-5/internal/units"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAction(t *testing.T) {
+	a, err := ParseAction(" Flag ")
+	require.NoError(t, err)
+	assert.Equal(t, Flag, a)
+
+	_, err = ParseAction("warn")
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	rules := DefaultRules()
+
+	tests := []struct {
+		name     string
+		rec      Record
+		warnings []Check
+		reject   Check
+	}{
+		{
+			name: "правдоподобная прогулка",
+			rec:  Record{Kind: spentenergy.Walking, Steps: 6000, Duration: time.Hour, Speed: 4.5},
+		},
+		{
+			name:   "слишком высокий темп",
+			rec:    Record{Kind: spentenergy.Walking, Steps: 30000, Duration: time.Hour, Speed: 5},
+			reject: Cadence,
+		},
+		{
+			name:     "слишком быстрая ходьба только отмечается",
+			rec:      Record{Activity: "Ходьба", Kind: spentenergy.Walking, Steps: 6000, Duration: time.Hour, Speed: 12},
+			warnings: []Check{Speed},
+		},
+		{
+			name:   "запись длиннее суток",
+			rec:    Record{Kind: spentenergy.Walking, Steps: 1000, Duration: 25 * time.Hour, Speed: 0.1},
+			reject: Duration,
+		},
+		{
+			name: "скорость без вида активности не проверяется",
+			rec:  Record{Activity: "Плавание", Steps: 1000, Duration: time.Hour, Speed: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := rules.Check(tt.rec)
+			if tt.reject != "" {
+				var v Violation
+				require.ErrorAs(t, err, &v)
+				assert.Equal(t, tt.reject, v.Check)
+				assert.ErrorIs(t, err, ErrImplausible)
+				assert.Empty(t, warnings)
+				return
+			}
+			require.NoError(t, err)
+			var checks []Check
+			for _, w := range warnings {
+				checks = append(checks, w.Check)
+			}
+			assert.Equal(t, tt.warnings, checks)
+		})
+	}
+}
+
+func TestCheckActivityLimit(t *testing.T) {
+	rules := DefaultRules().Merge(Rules{MaxSpeed: map[string]float64{"Бег": 20}})
+	rec := Record{Activity: "Бег", Kind: spentenergy.Running, Steps: 10000, Duration: time.Hour, Speed: 25}
+
+	warnings, err := rules.Check(rec)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, Violation{Check: Speed, Value: 25, Limit: 20}, warnings[0],
+		"предел для типа тренировки важнее предела для вида активности")
+}
+
+func TestCheckNil(t *testing.T) {
+	var rules *Rules
+	warnings, err := rules.Check(Record{Steps: 1e6, Duration: time.Minute, Speed: 1000})
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestWithAction(t *testing.T) {
+	rec := Record{Kind: spentenergy.Walking, Steps: 30000, Duration: 25 * time.Hour, Speed: 12}
+
+	flag := DefaultRules().WithAction(Flag)
+	warnings, err := flag.Check(rec)
+	require.NoError(t, err)
+	assert.Len(t, warnings, 2)
+
+	off := DefaultRules().WithAction(Off)
+	warnings, err = off.Check(rec)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	assert.Equal(t, Flag, DefaultRules().Actions[Speed], "WithAction не меняет исходные правила")
+}
+
+func TestMerge(t *testing.T) {
+	rules := DefaultRules().Merge(Rules{
+		MaxCadence: 200,
+		MaxSpeed:   map[string]float64{spentenergy.Running: 25},
+		Actions:    map[Check]Action{Speed: Reject},
+	})
+	assert.Equal(t, 200.0, rules.MaxCadence)
+	assert.Equal(t, 24*time.Hour, rules.MaxDuration)
+	assert.Equal(t, map[string]float64{spentenergy.Walking: 10, spentenergy.Running: 25}, rules.MaxSpeed)
+	assert.Equal(t, map[Check]Action{Cadence: Reject, Speed: Reject, Duration: Reject}, rules.Actions)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, DefaultRules().Validate())
+
+	tests := []struct {
+		rules Rules
+		field string
+	}{
+		{Rules{MaxCadence: -1}, "max_cadence_spm"},
+		{Rules{MaxSpeed: map[string]float64{"running": -5}}, "max_speed_kmh.running"},
+		{Rules{MaxDuration: -time.Hour}, "max_duration"},
+		{Rules{Actions: map[Check]Action{"pace": Flag}}, "actions.pace"},
+		{Rules{Actions: map[Check]Action{Speed: "warn"}}, "actions.speed"},
+	}
+	for _, tt := range tests {
+		var fe *FieldError
+		require.ErrorAs(t, tt.rules.Validate(), &fe)
+		assert.Equal(t, tt.field, fe.Field)
+	}
+}
+
+func TestRulesJSON(t *testing.T) {
+	data, err := json.Marshal(DefaultRules())
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"max_duration":"24h0m0s"`)
+
+	var rules Rules
+	require.NoError(t, json.Unmarshal(data, &rules))
+	assert.Equal(t, DefaultRules(), rules)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"max_duration": "PT12H"}`), &rules))
+	assert.Equal(t, 12*time.Hour, rules.MaxDuration)
+
+	var fe *FieldError
+	require.ErrorAs(t, json.Unmarshal([]byte(`{"max_duration": "сутки"}`), &rules), &fe)
+	assert.Equal(t, "max_duration", fe.Field)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"max_pace": 3}`), &rules), "неизвестные поля запрещены")
+}
+
+func TestViolationError(t *testing.T) {
+	v := Violation{Check: Speed, Value: 16.09344, Limit: 8.04672}
+	assert.Equal(t, "неправдоподобная скорость: 16.09 км/ч, допускается не больше 8.05", v.Error())
+	assert.True(t, errors.Is(v, ErrImplausible))
+
+	units.SetSystem(units.Imperial)
+	i18n.SetLocale(i18n.EN)
+	defer units.SetSystem(units.Metric)
+	defer i18n.SetLocale(i18n.RU)
+	assert.Equal(t, "implausible speed: 10.00 mph, at most 5.00 allowed", v.Error())
+
+	d := Violation{Check: Duration, Value: 25.5, Limit: 24}
+	assert.Equal(t, "implausible duration: 25h30m0s, at most 24h0m0s allowed", d.Error())
+}
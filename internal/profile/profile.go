@@ -0,0 +1,311 @@
+// Package profile хранит профили пользователей в файле настроек.
+// Путь к файлу задаёт вызывающий код; tracker хранит его в каталоге
+// данных, по умолчанию — в пользовательском каталоге настроек.
+//
+// Файл настроек — JSON-объект с именованными профилями и именем
+// профиля по умолчанию:
+//
+//	{
+//	  "default": "vitya",
+//	  "profiles": {
+//	    "vitya": {"name": "Витя", "weight_kg": 84.6, "height_m": 1.87}
+//	  }
+//	}
+//
+// Необязательный раздел "plausibility" задаёт правила проверки
+// правдоподобия записей (см. plausibility.Rules); заданные в нём пределы
+// и действия заменяют правила по умолчанию:
+//
+//	"plausibility": {"max_speed_kmh": {"running": 25}, "actions": {"speed": "reject"}}
+//
+// Ошибки в файле сообщают путь к полю, например profiles.vitya.weight_kg.
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"FINAL-PROJECT-5/internal/atomicfile"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+)
+
+// ErrNotFound возвращается, если файла настроек или профиля нет.
+var ErrNotFound = errors.New("profile not found")
+
+// notFoundError — ошибка для отсутствующего файла настроек или профиля:
+// сообщение локализовано, а errors.Is сопоставляет её с ErrNotFound.
+type notFoundError struct {
+	msg string
+}
+
+func (e notFoundError) Error() string {
+	return e.msg
+}
+
+func (e notFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// FieldError — ошибка в поле файла настроек. Path — путь к полю
+// через точку, например profiles.vitya.weight_kg.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Config — содержимое файла настроек.
+type Config struct {
+	Default  string                           `json:"default,omitempty"`
+	Profiles map[string]personaldata.Personal `json:"profiles"`
+
+	// Plausibility — правила проверки правдоподобия записей; если nil,
+	// действуют plausibility.DefaultRules.
+	Plausibility *plausibility.Rules `json:"plausibility,omitempty"`
+}
+
+// Rules возвращает правила проверки правдоподобия: правила по умолчанию,
+// дополненные разделом plausibility файла настроек.
+func (c Config) Rules() plausibility.Rules {
+	rules := plausibility.DefaultRules()
+	if c.Plausibility != nil {
+		rules = rules.Merge(*c.Plausibility)
+	}
+	return rules
+}
+
+// Names возвращает имена профилей по алфавиту.
+func (c Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve возвращает имя профиля, который следует использовать:
+// name, если оно задано, иначе профиль по умолчанию, а если и он
+// не задан — единственный профиль.
+func (c Config) Resolve(name string) (string, error) {
+	switch {
+	case name != "":
+	case c.Default != "":
+		name = c.Default
+	case len(c.Profiles) == 1:
+		name = c.Names()[0]
+	case len(c.Profiles) == 0:
+		return "", notFoundError{i18n.T("не задано ни одного профиля")}
+	default:
+		return "", i18n.Errorf("профилей несколько, а профиль по умолчанию не задан: выберите один из %s",
+			strings.Join(c.Names(), ", "))
+	}
+
+	if _, ok := c.Profiles[name]; !ok {
+		return "", notFoundError{i18n.T("профиль не найден: %s", name)}
+	}
+	return name, nil
+}
+
+// Profile возвращает профиль по имени; правила выбора — как у Resolve.
+func (c Config) Profile(name string) (personaldata.Personal, error) {
+	name, err := c.Resolve(name)
+	if err != nil {
+		return personaldata.Personal{}, err
+	}
+	return c.Profiles[name], nil
+}
+
+// Set сохраняет профиль p под именем name. Первый добавленный профиль
+// становится профилем по умолчанию.
+func (c *Config) Set(name string, p personaldata.Personal) {
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]personaldata.Personal)
+	}
+	c.Profiles[name] = p
+	if c.Default == "" {
+		c.Default = name
+	}
+}
+
+// Validate проверяет все профили. Ошибка имеет тип *FieldError.
+func (c Config) Validate() error {
+	if c.Default != "" {
+		if _, ok := c.Profiles[c.Default]; !ok {
+			return &FieldError{Path: "default", Err: i18n.Errorf("профиль не найден: %s", c.Default)}
+		}
+	}
+	for _, name := range c.Names() {
+		if strings.TrimSpace(name) == "" {
+			return &FieldError{Path: "profiles", Err: i18n.Errorf("имя профиля не может быть пустым")}
+		}
+		if err := c.Profiles[name].Validate(); err != nil {
+			return &FieldError{Path: profilePath(name, fieldOf(err)), Err: err}
+		}
+	}
+	if c.Plausibility != nil {
+		if err := c.Plausibility.Validate(); err != nil {
+			return plausibilityError(err)
+		}
+	}
+	return nil
+}
+
+// plausibilityError переводит ошибку в разделе plausibility в *FieldError
+// с полным путём к полю.
+func plausibilityError(err error) error {
+	var fe *plausibility.FieldError
+	if errors.As(err, &fe) {
+		return &FieldError{Path: "plausibility." + fe.Field, Err: fe.Err}
+	}
+	return err
+}
+
+func fieldOf(err error) string {
+	var fe *personaldata.FieldError
+	if errors.As(err, &fe) {
+		return fe.Field
+	}
+	return ""
+}
+
+func profilePath(name, field string) string {
+	path := "profiles." + name
+	if field != "" {
+		path += "." + field
+	}
+	return path
+}
+
+// Load читает и проверяет файл настроек. Если файла нет, возвращается
+// ошибка, для которой errors.Is(err, ErrNotFound) истинно.
+func Load(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, notFoundError{i18n.T("файл настроек не найден: %s", path)}
+	}
+	if err != nil {
+		return Config{}, i18n.Errorf("ошибка чтения настроек: %w", err)
+	}
+
+	c, err := decode(data)
+	if err == nil {
+		err = c.Validate()
+	}
+	if err != nil {
+		return Config{}, i18n.Errorf("ошибка в файле настроек %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// decode разбирает файл настроек и сообщает о неизвестных полях
+// и значениях неверного типа с указанием пути к полю.
+func decode(data []byte) (Config, error) {
+	var raw struct {
+		Default  string                     `json:"default"`
+		Profiles map[string]json.RawMessage `json:"profiles"`
+
+		Plausibility json.RawMessage `json:"plausibility"`
+	}
+	if err := strictUnmarshal(data, &raw); err != nil {
+		return Config{}, jsonError(data, "", err)
+	}
+
+	c := Config{Default: raw.Default, Profiles: make(map[string]personaldata.Personal, len(raw.Profiles))}
+	for name, msg := range raw.Profiles {
+		var p personaldata.Personal
+		if err := strictUnmarshal(msg, &p); err != nil {
+			return Config{}, jsonError(msg, "profiles."+name, err)
+		}
+		c.Profiles[name] = p
+	}
+
+	if raw.Plausibility != nil {
+		c.Plausibility = new(plausibility.Rules)
+		if err := strictUnmarshal(raw.Plausibility, c.Plausibility); err != nil {
+			return Config{}, plausibilityError(jsonError(raw.Plausibility, "plausibility", err))
+		}
+	}
+	return c, nil
+}
+
+func strictUnmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// jsonError дополняет ошибку encoding/json путём к полю или позицией
+// в файле. prefix — путь к разбираемому объекту.
+func jsonError(data []byte, prefix string, err error) error {
+	join := func(field string) string {
+		if prefix == "" {
+			return field
+		}
+		return prefix + "." + field
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return &FieldError{Path: join(typeErr.Field), Err: i18n.Errorf("неверный тип значения: %s", typeErr.Value)}
+	case errors.As(err, &syntaxErr):
+		line, col := position(data, syntaxErr.Offset)
+		return i18n.Errorf("синтаксическая ошибка в строке %d, столбце %d: %v", line, col, err)
+	}
+
+	// encoding/json не экспортирует тип ошибки для неизвестного поля.
+	if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return &FieldError{Path: join(strings.Trim(field, `"`)), Err: i18n.Errorf("неизвестное поле")}
+	}
+	return err
+}
+
+// position возвращает строку и столбец (с единицы) байта offset в data.
+func position(data []byte, offset int64) (line, col int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	col = len(before) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
+
+// Save проверяет настройки и атомарно записывает их в файл path,
+// создавая каталог при необходимости.
+func Save(path string, c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return i18n.Errorf("ошибка записи настроек: %w", err)
+	}
+	if err := atomicfile.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return i18n.Errorf("ошибка записи настроек: %w", err)
+	}
+	return nil
+}
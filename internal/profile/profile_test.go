@@ -0,0 +1,168 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	vitya = personaldata.Personal{Name: "Витя", Weight: 84.6, Height: 1.87, Age: 30, Sex: personaldata.SexMale}
+	anna  = personaldata.Personal{Name: "Анна", Weight: 60, Height: 1.65}
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tracker", "config.json")
+
+	_, err := Load(path)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var c Config
+	c.Set("vitya", vitya)
+	c.Set("anna", anna)
+	assert.Equal(t, "vitya", c.Default, "первый профиль становится профилем по умолчанию")
+	require.NoError(t, Save(path, c))
+
+	got, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, c, got)
+	assert.Equal(t, []string{"anna", "vitya"}, got.Names())
+
+	bad := got
+	bad.Profiles = map[string]personaldata.Personal{"vitya": {Name: "Витя"}}
+	assert.Error(t, Save(path, bad), "недопустимые настройки не сохраняются")
+	got, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, c, got, "прежние настройки не должны измениться")
+}
+
+func TestResolve(t *testing.T) {
+	var empty Config
+	_, err := empty.Profile("")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	one := Config{Profiles: map[string]personaldata.Personal{"anna": anna}}
+	p, err := one.Profile("")
+	require.NoError(t, err)
+	assert.Equal(t, anna, p, "единственный профиль выбирается без имени")
+
+	two := Config{Profiles: map[string]personaldata.Personal{"anna": anna, "vitya": vitya}}
+	_, err = two.Profile("")
+	assert.Error(t, err, "без профиля по умолчанию выбор неоднозначен")
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	two.Default = "vitya"
+	p, err = two.Profile("")
+	require.NoError(t, err)
+	assert.Equal(t, vitya, p)
+
+	p, err = two.Profile("anna")
+	require.NoError(t, err)
+	assert.Equal(t, anna, p)
+
+	_, err = two.Profile("petya")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantPath string
+		wantText string
+	}{
+		{
+			name:     "недопустимое значение",
+			data:     `{"profiles": {"vitya": {"name": "Витя", "weight_kg": 0, "height_m": 1.87}}}`,
+			wantPath: "profiles.vitya.weight_kg",
+		},
+		{
+			name:     "неверный тип",
+			data:     `{"profiles": {"vitya": {"name": "Витя", "weight_kg": "много", "height_m": 1.87}}}`,
+			wantPath: "profiles.vitya.weight_kg",
+		},
+		{
+			name:     "неизвестное поле профиля",
+			data:     `{"profiles": {"vitya": {"name": "Витя", "weigth_kg": 84.6, "height_m": 1.87}}}`,
+			wantPath: "profiles.vitya.weigth_kg",
+		},
+		{
+			name:     "неизвестное поле верхнего уровня",
+			data:     `{"profile": {}}`,
+			wantPath: "profile",
+		},
+		{
+			name:     "профиль по умолчанию не существует",
+			data:     `{"default": "petya", "profiles": {"vitya": {"name": "Витя", "weight_kg": 84.6, "height_m": 1.87}}}`,
+			wantPath: "default",
+		},
+		{
+			name:     "неизвестное поле правил",
+			data:     `{"plausibility": {"max_pace": 3}}`,
+			wantPath: "plausibility.max_pace",
+		},
+		{
+			name:     "неверная продолжительность в правилах",
+			data:     `{"plausibility": {"max_duration": "сутки"}}`,
+			wantPath: "plausibility.max_duration",
+		},
+		{
+			name:     "неизвестное действие",
+			data:     `{"plausibility": {"actions": {"speed": "warn"}}}`,
+			wantPath: "plausibility.actions.speed",
+		},
+		{
+			name:     "синтаксическая ошибка",
+			data:     "{\n  \"profiles\": {,\n}",
+			wantText: "строке 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.data), 0o644))
+
+			_, err := Load(path)
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, ErrNotFound)
+			assert.Contains(t, err.Error(), path)
+
+			if tt.wantPath != "" {
+				var fe *FieldError
+				require.ErrorAs(t, err, &fe)
+				assert.Equal(t, tt.wantPath, fe.Path)
+			}
+			if tt.wantText != "" {
+				assert.Contains(t, err.Error(), tt.wantText)
+			}
+		})
+	}
+}
+
+func TestRules(t *testing.T) {
+	assert.Equal(t, plausibility.DefaultRules(), Config{}.Rules())
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"profiles": {}, "plausibility": {"max_duration": "12:00:00", "actions": {"speed": "reject"}}}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	c, err := Load(path)
+	require.NoError(t, err)
+	rules := c.Rules()
+	assert.Equal(t, 12*time.Hour, rules.MaxDuration)
+	assert.Equal(t, plausibility.Reject, rules.Actions[plausibility.Speed])
+	assert.Equal(t, 250.0, rules.MaxCadence, "незаданные пределы берутся из правил по умолчанию")
+
+	require.NoError(t, Save(path, c))
+	saved, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, c, saved)
+}
@@ -0,0 +1,135 @@
+package record
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// isoDuration — продолжительность ISO 8601 вида PnDTnHnMnS. Дробной
+// может быть любая часть; в качестве десятичного знака допускается
+// и точка, и запятая.
+var isoDuration = regexp.MustCompile(`^P(?:(\d+(?:[.,]\d+)?)D)?(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// ParseDuration разбирает продолжительность в одном из форматов:
+//
+//	1h30m, 45m, 90s  — формат Go (time.ParseDuration);
+//	1:30:00, 12:40   — часы, минуты и секунды или минуты и секунды;
+//	PT1H30M, P1DT2H  — ISO 8601;
+//	45, 12.5         — число без единицы — минуты.
+//
+// Знак допускается только в формате Go; проверка того, что
+// продолжительность больше нуля, остаётся вызывающему.
+func ParseDuration(s string) (time.Duration, error) {
+	switch {
+	case s == "":
+	case strings.HasPrefix(s, "P"):
+		return parseISODuration(s)
+	case strings.Contains(s, ":"):
+		return parseClockDuration(s)
+	case isNumber(s):
+		minutes, err := strconv.ParseFloat(s, 64)
+		if err == nil {
+			return toDuration(minutes, time.Minute)
+		}
+	default:
+		if d, err := time.ParseDuration(s); err == nil {
+			return d, nil
+		}
+	}
+	return 0, i18n.Errorf("неверный формат продолжительности: %q", s)
+}
+
+// parseClockDuration разбирает продолжительность вида Ч:ММ:СС или ММ:СС.
+// Минуты и секунды после первого разделителя должны быть меньше 60,
+// секунды могут быть дробными.
+func parseClockDuration(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, i18n.Errorf("неверный формат продолжительности: %q", s)
+	}
+
+	var total time.Duration
+	for i, p := range parts {
+		last := i == len(parts)-1
+		if p == "" || !isDigits(p) && !(last && isNumber(p)) {
+			return 0, i18n.Errorf("неверный формат продолжительности: %q", s)
+		}
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil || i > 0 && (v >= 60 || len(p) < 2) {
+			return 0, i18n.Errorf("неверный формат продолжительности: %q", s)
+		}
+
+		unit := time.Second
+		switch len(parts) - 1 - i {
+		case 1:
+			unit = time.Minute
+		case 2:
+			unit = time.Hour
+		}
+		d, err := toDuration(v, unit)
+		if err != nil {
+			return 0, i18n.Errorf("неверный формат продолжительности: %q", s)
+		}
+		total += d
+	}
+	return total, nil
+}
+
+// parseISODuration разбирает продолжительность ISO 8601 вида PnDTnHnMnS.
+// Сутки считаются равными 24 часам.
+func parseISODuration(s string) (time.Duration, error) {
+	m := isoDuration.FindStringSubmatch(s)
+	// Регулярное выражение допускает пустые части: "P" и "PT" без чисел
+	// — не продолжительность.
+	if m == nil || s[len(s)-1] == 'P' || s[len(s)-1] == 'T' {
+		return 0, i18n.Errorf("неверный формат продолжительности: %q", s)
+	}
+
+	var total time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+1] == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.Replace(m[i+1], ",", ".", 1), 64)
+		if err != nil {
+			return 0, i18n.Errorf("неверный формат продолжительности: %q", s)
+		}
+		d, err := toDuration(v, unit)
+		if err != nil {
+			return 0, i18n.Errorf("неверный формат продолжительности: %q", s)
+		}
+		total += d
+	}
+	return total, nil
+}
+
+// toDuration переводит v единиц unit в продолжительность.
+func toDuration(v float64, unit time.Duration) (time.Duration, error) {
+	d := v * float64(unit)
+	if d > math.MaxInt64 {
+		return 0, i18n.Errorf("слишком большая продолжительность")
+	}
+	return time.Duration(math.Round(d)), nil
+}
+
+// isDigits сообщает, состоит ли s только из цифр.
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isNumber сообщает, записано ли в s неотрицательное десятичное число
+// вида 12 или 12.5.
+func isNumber(s string) bool {
+	whole, frac, ok := strings.Cut(s, ".")
+	return isDigits(whole) && (!ok || isDigits(frac))
+}
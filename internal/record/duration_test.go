@@ -0,0 +1,69 @@
+package record
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		// Формат Go.
+		{input: "1h30m", want: 90 * time.Minute},
+		{input: "1.5h", want: 90 * time.Minute},
+		{input: "-1h", want: -time.Hour},
+		{input: "1.5d", wantErr: true},
+
+		// Часы, минуты и секунды.
+		{input: "12:40:00", want: 12*time.Hour + 40*time.Minute},
+		{input: "1:05:30", want: time.Hour + 5*time.Minute + 30*time.Second},
+		{input: "42:30", want: 42*time.Minute + 30*time.Second},
+		{input: "90:00", want: 90 * time.Minute},
+		{input: "4:55.5", want: 4*time.Minute + 55500*time.Millisecond},
+		{input: "1:75:00", wantErr: true},
+		{input: "1:5", wantErr: true},
+		{input: "1::00", wantErr: true},
+		{input: "1:00:00:00", wantErr: true},
+		{input: "-1:00", wantErr: true},
+		{input: "1.5:00", wantErr: true},
+
+		// ISO 8601.
+		{input: "PT45M", want: 45 * time.Minute},
+		{input: "PT1H5M30S", want: time.Hour + 5*time.Minute + 30*time.Second},
+		{input: "P1DT2H", want: 26 * time.Hour},
+		{input: "P1D", want: 24 * time.Hour},
+		{input: "PT0.5H", want: 30 * time.Minute},
+		{input: "PT1,5S", want: 1500 * time.Millisecond},
+		{input: "P", wantErr: true},
+		{input: "PT", wantErr: true},
+		{input: "P1DT", wantErr: true},
+		{input: "PT5M1H", wantErr: true},
+		{input: "P1W", wantErr: true},
+
+		// Минуты без единицы.
+		{input: "30", want: 30 * time.Minute},
+		{input: "12.5", want: 12*time.Minute + 30*time.Second},
+		{input: "12.", wantErr: true},
+
+		{input: "", wantErr: true},
+		{input: "час", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDuration(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package record
+
+import (
+	"errors"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// Коды ошибок разбора записей. Ошибки разбора имеют тип *FieldError,
+// а код ошибки проверяется через errors.Is:
+//
+//	if errors.Is(err, record.ErrInvalidDuration) { ... }
+var (
+	ErrMissingFields   error = &code{"missing_fields", "нехватка данных"}
+	ErrTooManyFields   error = &code{"too_many_fields", "лишние поля в записи"}
+	ErrInvalidTime     error = &code{"invalid_time", "неверный формат времени записи"}
+	ErrInvalidSteps    error = &code{"invalid_steps", "неверный формат количества шагов"}
+	ErrInvalidDuration error = &code{"invalid_duration", "неверный формат продолжительности"}
+	ErrNotPositive     error = &code{"not_positive", "значение должно быть больше нуля"}
+	ErrInvalidType     error = &code{"invalid_type", "неверный формат типа тренировки"}
+	ErrUnknownType     error = &code{"unknown_type", "неизвестный тип тренировки"}
+	ErrInvalidExtra    error = &code{"invalid_extra", "неверное дополнительное поле"}
+)
+
+// code — код ошибки разбора. Сообщение переводится при выводе.
+type code struct {
+	name string
+	msg  string
+}
+
+func (c *code) Error() string {
+	return i18n.T(c.msg)
+}
+
+// Code возвращает машиночитаемое имя кода ошибки разбора, например
+// "invalid_steps", или пустую строку, если err не ошибка разбора.
+func Code(err error) string {
+	var c *code
+	if errors.As(err, &c) {
+		return c.name
+	}
+	return ""
+}
+
+// Имена полей записей в FieldError.Field.
+const (
+	FieldTime     = "time"
+	FieldSteps    = "steps"
+	FieldType     = "type"
+	FieldDuration = "duration"
+)
+
+// FieldError — ошибка в поле записи.
+type FieldError struct {
+	// Field — имя поля: FieldTime, FieldSteps, FieldType, FieldDuration
+	// или ключ дополнительного поля тренировки, например "hr".
+	// Пусто, если ошибка относится к записи целиком.
+	Field string
+	// Column — номер поля в записи, начиная с 1; 0 — запись целиком
+	// или номер поля неизвестен.
+	Column int
+	// Value — исходное значение поля или вся запись, если ошибка
+	// относится к записи целиком.
+	Value string
+	// Err — код ошибки, например ErrInvalidSteps.
+	Err error
+
+	msg string
+}
+
+// NewFieldError создаёт ошибку в поле записи с кодом code. Текст msg
+// уточняет код для конкретного поля; если msg равно nil, выводится код.
+func NewFieldError(field string, column int, value string, code, msg error) *FieldError {
+	e := &FieldError{Field: field, Column: column, Value: value, Err: code}
+	if msg != nil {
+		e.msg = msg.Error()
+	}
+	return e
+}
+
+func (e *FieldError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,39 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"FINAL-PROJECT-5/internal/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldError(t *testing.T) {
+	err := error(NewFieldError(FieldSteps, 2, "abc", ErrInvalidSteps, nil))
+	assert.ErrorIs(t, err, ErrInvalidSteps)
+	assert.NotErrorIs(t, err, ErrInvalidDuration)
+	assert.Equal(t, "неверный формат количества шагов", err.Error(), "без уточнения выводится код")
+
+	err = NewFieldError(FieldDuration, 3, "-1h", ErrNotPositive, errors.New("продолжительность должна быть больше нуля"))
+	assert.Equal(t, "продолжительность должна быть больше нуля", err.Error())
+
+	var fe *FieldError
+	require.ErrorAs(t, err, &fe)
+	assert.Equal(t, FieldDuration, fe.Field)
+	assert.Equal(t, 3, fe.Column)
+	assert.Equal(t, "-1h", fe.Value)
+
+	assert.Equal(t, "not_positive", Code(fmt.Errorf("запись 3: %w", err)))
+	assert.Equal(t, "", Code(errors.New("другая ошибка")))
+}
+
+func TestCodeLocalized(t *testing.T) {
+	t.Cleanup(func() { i18n.SetLocale(i18n.DefaultLocale) })
+
+	i18n.SetLocale(i18n.EN)
+	assert.Equal(t, "not enough data", ErrMissingFields.Error())
+	assert.Equal(t, "value must be greater than zero", NewFieldError("", 0, "", ErrNotPositive, nil).Error())
+}
@@ -0,0 +1,176 @@
+package record
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// Mode — режим разбора записей.
+type Mode string
+
+const (
+	// Default — режим по умолчанию, нулевое значение Mode: каждый вид
+	// записей разбирается так же, как до появления режимов. Пробелы
+	// по краям отбрасываются в тех полях, где их отбрасывал прежний
+	// разбор (см. ParseOptions.TrimDefault); в остальном режим совпадает
+	// со Strict.
+	Default Mode = ""
+	// Strict — строгий режим для проверенных данных с устройств: поля
+	// записываются точно по формату, без пробелов по краям, разделителей
+	// разрядов и лишних полей.
+	Strict Mode = "strict"
+	// Lenient — нестрогий режим для данных, введённых вручную: пробелы
+	// по краям полей отбрасываются, в количестве шагов допускаются
+	// разделители разрядов ("1 000", "1'000", "1_000"), тип тренировки
+	// сравнивается без учёта регистра, а лишние поля в конце записи,
+	// в том числе пустые после завершающей запятой, пропускаются.
+	Lenient Mode = "lenient"
+)
+
+// ParseMode распознаёт режим разбора по названию без учёта регистра.
+// Пустая строка и "default" означают режим Default.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case Default, "default":
+		return Default, nil
+	case Strict, Lenient:
+		return m, nil
+	}
+	return "", i18n.Errorf("неизвестный режим разбора: %s", s)
+}
+
+// ParseOptions — настройки разбора записей. Нулевое значение
+// соответствует режиму Default.
+type ParseOptions struct {
+	Mode Mode
+}
+
+// Lenient сообщает, включён ли нестрогий режим.
+func (o ParseOptions) Lenient() bool {
+	return o.Mode == Lenient
+}
+
+// TrimDefault отбрасывает пробелы по краям value в режиме Default
+// и возвращает value как есть в остальных режимах. Виды записей
+// применяют его к полям, которые прежний разбор очищал от пробелов.
+func (o ParseOptions) TrimDefault(value string) string {
+	if o.Mode == Default {
+		return strings.TrimSpace(value)
+	}
+	return value
+}
+
+// Split разбивает запись на поля и отделяет метку времени (см. SplitTime).
+// Кроме полей, возвращает номер первого из них в записи. В нестрогом
+// режиме поля очищаются от пробелов по краям, а пустые поля в конце
+// записи отбрасываются.
+func (o ParseOptions) Split(datastring string) (time.Time, []string, int, error) {
+	fields := strings.Split(datastring, ",")
+	if o.Lenient() {
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		for len(fields) > 0 && fields[len(fields)-1] == "" {
+			fields = fields[:len(fields)-1]
+		}
+	} else if len(fields) > 0 && looksLikeTime(fields[0]) && hasSpaces(fields[0]) {
+		return time.Time{}, nil, 0, NewFieldError(FieldTime, 1, fields[0], ErrInvalidTime,
+			i18n.Errorf("метка времени не должна содержать пробелов в начале или в конце"))
+	}
+
+	t, parts, err := SplitTime(fields)
+	if err != nil {
+		return time.Time{}, nil, 0, err
+	}
+	return t, parts, len(fields) - len(parts) + 1, nil
+}
+
+// Steps разбирает количество шагов из поля с номером column.
+func (o ParseOptions) Steps(value string, column int) (int, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, NewFieldError(FieldSteps, column, value, ErrInvalidSteps,
+			i18n.Errorf("количество шагов не может быть пустым"))
+	}
+
+	s := value
+	if o.Lenient() {
+		var ok bool
+		if s, ok = stripThousands(strings.TrimSpace(s)); !ok {
+			return 0, NewFieldError(FieldSteps, column, value, ErrInvalidSteps, nil)
+		}
+	} else if hasSpaces(s) {
+		return 0, NewFieldError(FieldSteps, column, value, ErrInvalidSteps,
+			i18n.Errorf("количество шагов не должно содержать пробелов в начале или в конце"))
+	}
+
+	steps, err := strconv.Atoi(s)
+	if err != nil {
+		log.Println(i18n.T("Ошибка при преобразовании количества шагов:"), err)
+		return 0, NewFieldError(FieldSteps, column, value, ErrInvalidSteps, nil)
+	}
+	if steps <= 0 {
+		return 0, NewFieldError(FieldSteps, column, value, ErrNotPositive,
+			i18n.Errorf("количество шагов должно быть больше нуля"))
+	}
+	return steps, nil
+}
+
+// Duration разбирает продолжительность из поля с номером column
+// в любом из форматов ParseDuration.
+func (o ParseOptions) Duration(value string, column int) (time.Duration, error) {
+	s := value
+	if o.Lenient() {
+		s = strings.TrimSpace(s)
+	} else if hasSpaces(s) {
+		return 0, NewFieldError(FieldDuration, column, value, ErrInvalidDuration,
+			i18n.Errorf("продолжительность не должна содержать пробелов в начале или в конце"))
+	}
+
+	duration, err := ParseDuration(s)
+	if err != nil {
+		log.Println(i18n.T("Ошибка при преобразовании продолжительности:"), err)
+		return 0, NewFieldError(FieldDuration, column, value, ErrInvalidDuration, nil)
+	}
+	if duration <= 0 {
+		return 0, NewFieldError(FieldDuration, column, value, ErrNotPositive,
+			i18n.Errorf("продолжительность должна быть больше нуля"))
+	}
+	return duration, nil
+}
+
+// hasSpaces сообщает, есть ли в начале или в конце s пробельные символы.
+func hasSpaces(s string) bool {
+	return s != strings.TrimSpace(s)
+}
+
+// thousandsSeparators — допустимые разделители разрядов: пробел,
+// неразрывные пробелы, апостроф и подчёркивание.
+const thousandsSeparators = " \u00a0\u202f'_"
+
+// stripThousands убирает из числа разделители разрядов. Разделитель
+// в числе должен быть один и тот же, а группы после первой — ровно
+// по три цифры; иначе возвращается false.
+func stripThousands(s string) (string, bool) {
+	i := strings.IndexAny(s, thousandsSeparators)
+	if i < 0 {
+		return s, true
+	}
+	sep, _ := utf8.DecodeRuneInString(s[i:])
+
+	groups := strings.Split(s, string(sep))
+	for j, g := range groups {
+		digits := g
+		if j == 0 {
+			digits = strings.TrimPrefix(g, "+")
+		}
+		if strings.ContainsAny(g, thousandsSeparators) || len(digits) == 0 || len(digits) > 3 || j > 0 && len(digits) != 3 {
+			return "", false
+		}
+	}
+	return strings.Join(groups, ""), true
+}
@@ -0,0 +1,125 @@
+package record
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	strict  = ParseOptions{Mode: Strict}
+	lenient = ParseOptions{Mode: Lenient}
+)
+
+func TestParseMode(t *testing.T) {
+	m, err := ParseMode(" Lenient ")
+	require.NoError(t, err)
+	assert.Equal(t, Lenient, m)
+
+	m, err = ParseMode("strict")
+	require.NoError(t, err)
+	assert.Equal(t, Strict, m)
+
+	for _, s := range []string{"", "Default"} {
+		m, err = ParseMode(s)
+		require.NoError(t, err)
+		assert.Equal(t, Default, m, s)
+	}
+
+	_, err = ParseMode("loose")
+	assert.Error(t, err)
+
+	assert.Equal(t, Default, ParseOptions{}.Mode, "нулевые настройки — режим по умолчанию")
+	assert.False(t, strict.Lenient())
+}
+
+func TestTrimDefault(t *testing.T) {
+	assert.Equal(t, "1h", ParseOptions{}.TrimDefault(" 1h "))
+	assert.Equal(t, " 1h ", strict.TrimDefault(" 1h "))
+	assert.Equal(t, " 1h ", lenient.TrimDefault(" 1h "))
+}
+
+func TestSplit(t *testing.T) {
+	_, parts, column, err := strict.Split("678,0h50m")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"678", "0h50m"}, parts)
+	assert.Equal(t, 1, column)
+
+	ts, parts, column, err := lenient.Split(" 2024-05-01T07:30:00Z , 678 , 0h50m ,, ")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2024, 5, 1, 7, 30, 0, 0, time.UTC).Equal(ts))
+	assert.Equal(t, []string{"678", "0h50m"}, parts)
+	assert.Equal(t, 2, column)
+
+	_, parts, _, err = strict.Split("678,0h50m,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"678", "0h50m", ""}, parts, "в строгом режиме пустые поля сохраняются")
+
+	_, _, _, err = strict.Split(" 2024-05-01T07:30:00Z,678,0h50m")
+	assert.ErrorIs(t, err, ErrInvalidTime)
+}
+
+func TestSteps(t *testing.T) {
+	tests := []struct {
+		value       string
+		wantStrict  int
+		wantLenient int
+		code        error // ошибка в строгом режиме
+		lenientCode error
+	}{
+		{value: "678", wantStrict: 678, wantLenient: 678},
+		{value: "+1000", wantStrict: 1000, wantLenient: 1000},
+		{value: " 678", code: ErrInvalidSteps, wantLenient: 678},
+		{value: "1 000", code: ErrInvalidSteps, wantLenient: 1000},
+		{value: "12 345", code: ErrInvalidSteps, wantLenient: 12345},
+		{value: "1'234'567", code: ErrInvalidSteps, wantLenient: 1234567},
+		{value: "10_000", code: ErrInvalidSteps, wantLenient: 10000},
+		{value: "1 00", code: ErrInvalidSteps, lenientCode: ErrInvalidSteps},
+		{value: "1000 000", code: ErrInvalidSteps, lenientCode: ErrInvalidSteps},
+		{value: "1 000_000", code: ErrInvalidSteps, lenientCode: ErrInvalidSteps},
+		{value: " 000", code: ErrInvalidSteps, lenientCode: ErrNotPositive},
+		{value: "", code: ErrInvalidSteps, lenientCode: ErrInvalidSteps},
+		{value: "0", code: ErrNotPositive, lenientCode: ErrNotPositive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := strict.Steps(tt.value, 1)
+			if tt.code != nil {
+				assert.ErrorIs(t, err, tt.code, "строгий режим")
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantStrict, got)
+			}
+
+			got, err = lenient.Steps(tt.value, 1)
+			if tt.lenientCode != nil {
+				assert.ErrorIs(t, err, tt.lenientCode, "нестрогий режим")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantLenient, got)
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	d, err := strict.Duration("1h30m", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, d)
+
+	_, err = strict.Duration("1h ", 2)
+	assert.ErrorIs(t, err, ErrInvalidDuration)
+	var fe *FieldError
+	require.ErrorAs(t, err, &fe)
+	assert.Equal(t, 2, fe.Column)
+
+	d, err = lenient.Duration(" 1h ", 2)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, d)
+
+	_, err = lenient.Duration("0s", 2)
+	assert.ErrorIs(t, err, ErrNotPositive)
+}
@@ -0,0 +1,46 @@
+// Package record содержит разбор полей, общих для записей о дневной
+// активности и о тренировках.
+package record
+
+import (
+	"strings"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// SplitTime отделяет от полей записи необязательную метку времени.
+// Метка записывается первым полем в формате ISO 8601 (RFC 3339)
+// с часовым поясом, например "2024-05-01T07:30:00+03:00".
+// Если первое поле не похоже на метку времени, возвращается нулевое
+// время и поля без изменений. Ошибка имеет тип *FieldError с кодом
+// ErrInvalidTime.
+func SplitTime(parts []string) (time.Time, []string, error) {
+	if len(parts) == 0 || !looksLikeTime(parts[0]) {
+		return time.Time{}, parts, nil
+	}
+
+	value := strings.TrimSpace(parts[0])
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, parts, NewFieldError(FieldTime, 1, value, ErrInvalidTime,
+			i18n.Errorf("неверный формат времени записи: %q", value))
+	}
+	return t, parts[1:], nil
+}
+
+// looksLikeTime сообщает, начинается ли поле с даты вида ГГГГ-ММ-ДД.
+// Количество шагов такой формы иметь не может, поэтому ошибку в метке
+// времени можно отличить от ошибки в количестве шагов.
+func looksLikeTime(field string) bool {
+	field = strings.TrimSpace(field)
+	if len(field) < len("2006-01-02") || field[4] != '-' || field[7] != '-' {
+		return false
+	}
+	for _, c := range field[:4] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
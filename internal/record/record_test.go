@@ -0,0 +1,66 @@
+package record
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitTime(t *testing.T) {
+	msk := time.FixedZone("", 3*60*60)
+
+	tests := []struct {
+		name      string
+		parts     []string
+		wantTime  time.Time
+		wantParts []string
+		wantErr   bool
+	}{
+		{
+			name:      "без метки времени",
+			parts:     []string{"678", "0h50m"},
+			wantParts: []string{"678", "0h50m"},
+		},
+		{
+			name:      "метка времени с часовым поясом",
+			parts:     []string{"2024-05-01T07:30:00+03:00", "678", "0h50m"},
+			wantTime:  time.Date(2024, 5, 1, 7, 30, 0, 0, msk),
+			wantParts: []string{"678", "0h50m"},
+		},
+		{
+			name:      "метка времени в UTC с пробелами",
+			parts:     []string{" 2024-05-01T04:30:00.5Z ", "678", "0h50m"},
+			wantTime:  time.Date(2024, 5, 1, 4, 30, 0, 500000000, time.UTC),
+			wantParts: []string{"678", "0h50m"},
+		},
+		{name: "нет часового пояса", parts: []string{"2024-05-01T07:30:00", "678", "0h50m"}, wantErr: true},
+		{name: "только дата", parts: []string{"2024-05-01", "678", "0h50m"}, wantErr: true},
+		{name: "неверная дата", parts: []string{"2024-13-01T07:30:00Z", "678", "0h50m"}, wantErr: true},
+		{name: "пустые поля", parts: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, rest, err := SplitTime(tt.parts)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.wantTime.Equal(got), "время: ожидалось %v, получено %v", tt.wantTime, got)
+			assert.Equal(t, tt.wantParts, rest)
+		})
+	}
+}
+
+func TestSplitTimeError(t *testing.T) {
+	_, _, err := SplitTime([]string{"2024-05-01T07:30:00", "678", "0h50m"})
+	var fe *FieldError
+	require.ErrorAs(t, err, &fe)
+	assert.ErrorIs(t, err, ErrInvalidTime)
+	assert.Equal(t, FieldTime, fe.Field)
+	assert.Equal(t, 1, fe.Column)
+	assert.Equal(t, "2024-05-01T07:30:00", fe.Value)
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// Format — формат вывода результатов.
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatCSV    Format = "csv"
+)
+
+// Formats — поддерживаемые форматы вывода.
+var Formats = []Format{FormatText, FormatJSON, FormatNDJSON, FormatCSV}
+
+// ParseFormat возвращает формат по названию без учёта регистра.
+func ParseFormat(s string) (Format, error) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Formats {
+		if f == known {
+			return f, nil
+		}
+	}
+	return "", i18n.Errorf("неизвестный формат вывода: %s", s)
+}
+
+// Encoder последовательно записывает результаты в выбранном формате.
+// Close дописывает завершающие данные формата и должен вызываться
+// после последнего Encode; сам io.Writer он не закрывает.
+type Encoder interface {
+	Encode(r Result) error
+	Close() error
+}
+
+// NewEncoder создаёт Encoder для формата f.
+func NewEncoder(w io.Writer, f Format) (Encoder, error) {
+	switch f {
+	case FormatText:
+		return &textEncoder{w: w}, nil
+	case FormatJSON:
+		return &jsonEncoder{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonEncoder{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return &csvEncoder{w: csv.NewWriter(w)}, nil
+	}
+	return nil, i18n.Errorf("неизвестный формат вывода: %s", f)
+}
+
+// Write записывает все результаты в формате f.
+func Write(w io.Writer, f Format, results []Result) error {
+	enc, err := NewEncoder(w, f)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+type textEncoder struct {
+	w io.Writer
+}
+
+func (e *textEncoder) Encode(r Result) error {
+	_, err := fmt.Fprintln(e.w, Text(r))
+	return err
+}
+
+func (e *textEncoder) Close() error {
+	return nil
+}
+
+// jsonEncoder пишет результаты одним JSON-массивом, не накапливая их в памяти.
+type jsonEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonEncoder) Encode(r Result) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n"
+	if e.count == 0 {
+		prefix = "[\n"
+	}
+	e.count++
+
+	_, err = fmt.Fprintf(e.w, "%s%s", prefix, data)
+	return err
+}
+
+func (e *jsonEncoder) Close() error {
+	if e.count == 0 {
+		_, err := io.WriteString(e.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(e.w, "\n]\n")
+	return err
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(r Result) error {
+	return e.enc.Encode(r)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+// csvHeader — заголовок CSV; порядок полей совпадает с csvRecord.
+var csvHeader = []string{
+	"kind", "type", "steps", "duration", "duration_hours", "distance_km",
+	"speed_kmh", "calories_kcal", "heart_rate", "bmr_kcal_per_day", "pace_s_per_km",
+	"time",
+}
+
+type csvEncoder struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (e *csvEncoder) Encode(r Result) error {
+	if !e.headerWritten {
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+
+	if err := e.w.Write(csvRecord(r)); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	if !e.headerWritten {
+		if err := e.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func csvRecord(r Result) []string {
+	return []string{
+		r.Kind,
+		r.Type,
+		strconv.Itoa(r.Steps),
+		r.Duration.String(),
+		formatFloat(r.Duration.Hours()),
+		formatFloat(r.Distance),
+		formatFloat(r.Speed),
+		formatFloat(r.Calories),
+		strconv.Itoa(r.HeartRate),
+		formatFloat(r.BMR),
+		formatFloat(r.Pace.Seconds()),
+		formatTime(r.Time),
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
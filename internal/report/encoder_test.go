@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sampleResults = []Result{
+	{Kind: KindDaySteps, Steps: 6000, Duration: time.Hour, Distance: 4.725, Speed: 4.725, Calories: 177.1875},
+	{Kind: KindTraining, Time: time.Date(2024, 5, 1, 7, 30, 0, 0, time.UTC), Type: "Бег", Steps: 3000, Duration: 30 * time.Minute, Distance: 2.3625, Speed: 4.725, Calories: 177.1875, HeartRate: 140},
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat(" JSON ")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, f)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJSON, sampleResults))
+
+	var decoded []Result
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded), "вывод должен быть корректным JSON-массивом: %s", buf.String())
+	assert.Equal(t, sampleResults, decoded)
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatJSON, nil))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatNDJSON, sampleResults))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	for i, line := range lines {
+		var decoded Result
+		require.NoError(t, json.Unmarshal([]byte(line), &decoded))
+		assert.Equal(t, sampleResults[i], decoded)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatCSV, sampleResults))
+
+	want := "kind,type,steps,duration,duration_hours,distance_km,speed_kmh,calories_kcal,heart_rate,bmr_kcal_per_day,pace_s_per_km,time\n" +
+		"daysteps,,6000,1h0m0s,1.00,4.72,4.72,177.19,0,0.00,0.00,\n" +
+		"training,Бег,3000,30m0s,0.50,2.36,4.72,177.19,140,0.00,0.00,2024-05-01T07:30:00Z\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatText, sampleResults[:1]))
+	assert.Equal(t, Text(sampleResults[0])+"\n", buf.String())
+}
+
+func TestNewEncoderUnknownFormat(t *testing.T) {
+	_, err := NewEncoder(&bytes.Buffer{}, Format("xml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,273 @@
+package report
+
+import (
+	"encoding/json"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/units"
+)
+
+// Виды записей.
+const (
+	KindDaySteps = "daysteps"
+	KindTraining = "training"
+)
+
+// Result — рассчитанные показатели одной записи об активности.
+type Result struct {
+	Kind     string        // KindDaySteps или KindTraining.
+	Time     time.Time     // время записи; нулевое, если неизвестно.
+	Type     string        // каноническое название типа тренировки из реестра; для дневной активности пусто.
+	Title    string        // название типа для текстового отчёта; пусто — перевод Type на текущий язык.
+	Steps    int           // количество шагов.
+	Duration time.Duration // продолжительность.
+	Distance float64       // дистанция, км.
+	Speed    float64       // средняя скорость, км/ч.
+	Calories float64       // потраченные калории, ккал.
+
+	// EstimatedCalories — расчётная оценка калорий, ккал, если Calories
+	// взяты из данных устройства; 0 — Calories и есть расчётная оценка
+	// либо оценку рассчитать не удалось.
+	EstimatedCalories float64
+
+	HeartRate int     // средний пульс, уд/мин; 0 — не измерен.
+	BMR       float64 // базовый обмен на дату записи, ккал/сут; 0 — не рассчитан.
+
+	Pace   time.Duration // темп, время на километр; 0 — не выводится.
+	Splits []Split       // отрезки (круги) тренировки.
+
+	Elapsed       time.Duration // общее время с остановками; 0 — совпадает с Duration.
+	ElevationGain float64       // набор высоты, м.
+	ElevationLoss float64       // сброс высоты, м.
+
+	Warnings []plausibility.Violation // нарушения правдоподобия, с которыми запись принята.
+}
+
+// Provider реализуют записи, которые умеют возвращать рассчитанные показатели.
+type Provider interface {
+	Result() (Result, error)
+}
+
+// jsonResult — представление Result в JSON. Продолжительность
+// записывается строкой в формате time.Duration и в часах. Title
+// не записывается: машиночитаемый вывод не зависит от языка.
+type jsonResult struct {
+	Kind              string  `json:"kind"`
+	Time              string  `json:"time,omitempty"`
+	Type              string  `json:"type,omitempty"`
+	Steps             int     `json:"steps"`
+	Duration          string  `json:"duration"`
+	DurationHours     float64 `json:"duration_hours"`
+	Distance          float64 `json:"distance_km"`
+	Speed             float64 `json:"speed_kmh"`
+	Calories          float64 `json:"calories_kcal"`
+	EstimatedCalories float64 `json:"estimated_calories_kcal,omitempty"`
+	HeartRate         int     `json:"heart_rate,omitempty"`
+	BMR               float64 `json:"bmr_kcal_per_day,omitempty"`
+
+	Pace          float64     `json:"pace_s_per_km,omitempty"`
+	Splits        []jsonSplit `json:"splits,omitempty"`
+	FastestSplit  *int        `json:"fastest_split,omitempty"`
+	SlowestSplit  *int        `json:"slowest_split,omitempty"`
+	NegativeSplit bool        `json:"negative_split,omitempty"`
+
+	Elapsed       string  `json:"elapsed,omitempty"`
+	ElevationGain float64 `json:"elevation_gain_m,omitempty"`
+	ElevationLoss float64 `json:"elevation_loss_m,omitempty"`
+
+	Warnings []plausibility.Violation `json:"warnings,omitempty"`
+}
+
+type jsonSplit struct {
+	Distance float64 `json:"distance_km"`
+	Duration string  `json:"duration"`
+	Pace     float64 `json:"pace_s_per_km"`
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	jr := jsonResult{
+		Kind:              r.Kind,
+		Type:              r.Type,
+		Steps:             r.Steps,
+		Duration:          r.Duration.String(),
+		DurationHours:     r.Duration.Hours(),
+		Distance:          r.Distance,
+		Speed:             r.Speed,
+		Calories:          r.Calories,
+		EstimatedCalories: r.EstimatedCalories,
+		HeartRate:         r.HeartRate,
+		BMR:               r.BMR,
+		Pace:              r.Pace.Seconds(),
+		Warnings:          r.Warnings,
+		ElevationGain:     r.ElevationGain,
+		ElevationLoss:     r.ElevationLoss,
+	}
+	if !r.Time.IsZero() {
+		jr.Time = r.Time.Format(time.RFC3339)
+	}
+	if r.Elapsed != 0 {
+		jr.Elapsed = r.Elapsed.String()
+	}
+
+	if len(r.Splits) > 0 {
+		if fastest, slowest := r.FastestSplit(), r.SlowestSplit(); fastest >= 0 {
+			jr.FastestSplit, jr.SlowestSplit = &fastest, &slowest
+		}
+		jr.NegativeSplit = r.NegativeSplit()
+	}
+	for _, split := range r.Splits {
+		jr.Splits = append(jr.Splits, jsonSplit{
+			Distance: split.Distance,
+			Duration: split.Duration.String(),
+			Pace:     split.Pace().Seconds(),
+		})
+	}
+
+	return json.Marshal(jr)
+}
+
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var jr jsonResult
+	if err := json.Unmarshal(data, &jr); err != nil {
+		return err
+	}
+
+	duration, err := time.ParseDuration(jr.Duration)
+	if err != nil {
+		return i18n.Errorf("неверный формат продолжительности: %w", err)
+	}
+
+	*r = Result{
+		Kind:              jr.Kind,
+		Type:              jr.Type,
+		Steps:             jr.Steps,
+		Duration:          duration,
+		Distance:          jr.Distance,
+		Speed:             jr.Speed,
+		Calories:          jr.Calories,
+		EstimatedCalories: jr.EstimatedCalories,
+		HeartRate:         jr.HeartRate,
+		BMR:               jr.BMR,
+		Pace:              time.Duration(jr.Pace * float64(time.Second)),
+		Warnings:          jr.Warnings,
+		ElevationGain:     jr.ElevationGain,
+		ElevationLoss:     jr.ElevationLoss,
+	}
+
+	if jr.Elapsed != "" {
+		elapsed, err := time.ParseDuration(jr.Elapsed)
+		if err != nil {
+			return i18n.Errorf("неверный формат продолжительности: %w", err)
+		}
+		r.Elapsed = elapsed
+	}
+
+	if jr.Time != "" {
+		t, err := time.Parse(time.RFC3339, jr.Time)
+		if err != nil {
+			return i18n.Errorf("неверный формат времени записи: %q", jr.Time)
+		}
+		r.Time = t
+	}
+
+	for _, split := range jr.Splits {
+		d, err := time.ParseDuration(split.Duration)
+		if err != nil {
+			return i18n.Errorf("неверный формат продолжительности: %w", err)
+		}
+		r.Splits = append(r.Splits, Split{Distance: split.Distance, Duration: d})
+	}
+	return nil
+}
+
+// textTimeLayout — формат времени записи в текстовом отчёте.
+const textTimeLayout = "2006-01-02 15:04 -07:00"
+
+// Text возвращает текстовый отчёт о записи на текущем языке
+// и в текущей системе единиц. Предупреждения о неправдоподобных
+// показателях выводятся в конце отчёта.
+func Text(r Result) string {
+	text := resultText(r)
+	for _, w := range r.Warnings {
+		text += i18n.T("Предупреждение: %s\n", w.Error())
+	}
+	return text
+}
+
+func resultText(r Result) string {
+	sys := units.Current()
+	distance, distanceUnit := sys.Distance(r.Distance)
+
+	var text string
+	if !r.Time.IsZero() {
+		text = i18n.T("Время: %s\n", r.Time.Format(textTimeLayout))
+	}
+
+	if r.Kind == KindDaySteps {
+		text += i18n.T("Количество шагов: %d.\nДистанция составила %.2f %s.\nВы сожгли %.2f ккал.\n",
+			r.Steps, distance, distanceUnit, r.Calories)
+		if r.BMR > 0 {
+			text += i18n.T("Базовый обмен: %.2f ккал/сут.\n", r.BMR)
+		}
+		return text
+	}
+
+	title := r.Title
+	if title == "" {
+		title = i18n.T(r.Type)
+	}
+	speed, speedUnit := sys.Speed(r.Speed)
+	text += i18n.T("Тип тренировки: %s\nДлительность: %.2f ч.\nДистанция: %.2f %s.\nСкорость: %.2f %s\nСожгли калорий: %.2f\n",
+		title, r.Duration.Hours(), distance, distanceUnit, speed, speedUnit, r.Calories)
+	if r.EstimatedCalories > 0 {
+		text += i18n.T("Калории — по данным устройства; по расчёту: %.2f (разница %+.0f%%)\n",
+			r.EstimatedCalories, (r.Calories/r.EstimatedCalories-1)*100)
+	}
+	if r.Elapsed > 0 {
+		text += i18n.T("Общее время: %.2f ч.\n", r.Elapsed.Hours())
+	}
+	if r.ElevationGain > 0 || r.ElevationLoss > 0 {
+		gain, unit := sys.Elevation(r.ElevationGain)
+		loss, _ := sys.Elevation(r.ElevationLoss)
+		text += i18n.T("Набор высоты: %.0f %s, сброс: %.0f %s\n", gain, unit, loss, unit)
+	}
+	if r.HeartRate > 0 {
+		text += i18n.T("Средний пульс: %d уд/мин\n", r.HeartRate)
+	}
+	if r.Pace > 0 {
+		pace, paceUnit := sys.Pace(r.Pace)
+		text += i18n.T("Темп: %s %s\n", units.FormatPace(pace), paceUnit)
+	}
+	if len(r.Splits) > 0 {
+		text += splitsText(r, sys)
+	}
+	return text
+}
+
+func splitsText(r Result, sys units.System) string {
+	formatPace := func(perKm time.Duration) string {
+		pace, unit := sys.Pace(perKm)
+		return units.FormatPace(pace) + " " + unit
+	}
+
+	text := i18n.T("Круги:\n")
+	for i, split := range r.Splits {
+		distance, distanceUnit := sys.Distance(split.Distance)
+		text += i18n.T("  %d. %.2f %s за %s, темп %s\n",
+			i+1, distance, distanceUnit, units.FormatPace(split.Duration), formatPace(split.Pace()))
+	}
+
+	if fastest, slowest := r.FastestSplit(), r.SlowestSplit(); fastest >= 0 {
+		text += i18n.T("Самый быстрый круг: %d (%s)\n", fastest+1, formatPace(r.Splits[fastest].Pace()))
+		text += i18n.T("Самый медленный круг: %d (%s)\n", slowest+1, formatPace(r.Splits[slowest].Pace()))
+	}
+
+	if r.NegativeSplit() {
+		text += i18n.T("Негативный сплит: да\n")
+	} else {
+		text += i18n.T("Негативный сплит: нет\n")
+	}
+	return text
+}
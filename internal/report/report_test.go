@@ -0,0 +1,132 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/units"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   string
+	}{
+		{
+			name:   "дневная активность",
+			result: Result{Kind: KindDaySteps, Steps: 6000, Duration: time.Hour, Distance: 4.725, Calories: 177.1875},
+			want:   "Количество шагов: 6000.\nДистанция составила 4.72 км.\nВы сожгли 177.19 ккал.\n",
+		},
+		{
+			name:   "дневная активность с базовым обменом",
+			result: Result{Kind: KindDaySteps, Steps: 6000, Distance: 4.725, Calories: 177.1875, BMR: 1698.75},
+			want:   "Количество шагов: 6000.\nДистанция составила 4.72 км.\nВы сожгли 177.19 ккал.\nБазовый обмен: 1698.75 ккал/сут.\n",
+		},
+		{
+			name:   "тренировка с пульсом",
+			result: Result{Kind: KindTraining, Type: "Бег", Duration: time.Hour, Distance: 4.725, Speed: 4.725, Calories: 354.375, HeartRate: 150},
+			want:   "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 4.72 км.\nСкорость: 4.72 км/ч\nСожгли калорий: 354.38\nСредний пульс: 150 уд/мин\n",
+		},
+		{
+			name: "тренировка с калориями устройства",
+			result: Result{Kind: KindTraining, Type: "Бег", Duration: time.Hour, Distance: 10, Speed: 10,
+				Calories: 780, EstimatedCalories: 750},
+			want: "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 10.00 км.\nСкорость: 10.00 км/ч\nСожгли калорий: 780.00\n" +
+				"Калории — по данным устройства; по расчёту: 750.00 (разница +4%)\n",
+		},
+		{
+			name: "дневная активность со временем",
+			result: Result{Kind: KindDaySteps, Time: time.Date(2024, 5, 1, 7, 30, 0, 0, time.FixedZone("", 3*60*60)),
+				Steps: 6000, Distance: 4.725, Calories: 177.1875},
+			want: "Время: 2024-05-01 07:30 +03:00\nКоличество шагов: 6000.\nДистанция составила 4.72 км.\nВы сожгли 177.19 ккал.\n",
+		},
+		{
+			name: "дневная активность с предупреждением",
+			result: Result{Kind: KindDaySteps, Steps: 6000, Distance: 4.725, Calories: 177.1875,
+				Warnings: []plausibility.Violation{{Check: plausibility.Speed, Value: 12, Limit: 10}}},
+			want: "Количество шагов: 6000.\nДистанция составила 4.72 км.\nВы сожгли 177.19 ккал.\n" +
+				"Предупреждение: неправдоподобная скорость: 12.00 км/ч, допускается не больше 10.00\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Text(tt.result))
+		})
+	}
+}
+
+func TestResultJSON(t *testing.T) {
+	r := Result{Kind: KindTraining, Type: "Бег", Steps: 6000, Duration: 90 * time.Minute, Distance: 4.5, Speed: 3, Calories: 300}
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"training","type":"Бег","steps":6000,"duration":"1h30m0s","duration_hours":1.5,
+		"distance_km":4.5,"speed_kmh":3,"calories_kcal":300}`, string(data))
+
+	var decoded Result
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, r, decoded)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"duration":"soon"}`), &decoded))
+}
+
+func TestResultJSONTrack(t *testing.T) {
+	r := Result{Kind: KindTraining, Type: "Бег", Duration: time.Hour, Distance: 10, Speed: 10,
+		Calories: 780, EstimatedCalories: 750, Elapsed: 70 * time.Minute, ElevationGain: 50, ElevationLoss: 40}
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"calories_kcal":780,"estimated_calories_kcal":750`)
+	assert.Contains(t, string(data), `"elapsed":"1h10m0s","elevation_gain_m":50,"elevation_loss_m":40`)
+
+	var decoded Result
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, r, decoded)
+}
+
+func TestResultJSONWarnings(t *testing.T) {
+	r := Result{Kind: KindDaySteps, Steps: 6000, Duration: time.Hour,
+		Warnings: []plausibility.Violation{{Check: plausibility.Speed, Value: 12, Limit: 10}}}
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"warnings":[{"check":"speed","value":12,"limit":10}]`)
+
+	var decoded Result
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, r, decoded)
+}
+
+func TestResultJSONTime(t *testing.T) {
+	r := Result{Kind: KindDaySteps, Time: time.Date(2024, 5, 1, 7, 30, 0, 0, time.FixedZone("", 3*60*60)), Duration: time.Hour}
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"time":"2024-05-01T07:30:00+03:00"`)
+
+	var decoded Result
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, r.Time.Equal(decoded.Time))
+	_, offset := decoded.Time.Zone()
+	assert.Equal(t, 3*60*60, offset, "часовой пояс должен сохраняться")
+
+	assert.Error(t, json.Unmarshal([]byte(`{"duration":"1h","time":"вчера"}`), &decoded))
+}
+
+func TestTextImperial(t *testing.T) {
+	defer units.SetSystem(units.Current())
+	units.SetSystem(units.Imperial)
+
+	r := Result{Kind: KindTraining, Type: "Бег", Duration: time.Hour, Distance: units.MilesToKm(6), Speed: units.MilesToKm(6), Calories: 700}
+	assert.Equal(t, "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 6.00 миль.\nСкорость: 6.00 миль/ч\nСожгли калорий: 700.00\n", Text(r))
+
+	r.ElevationGain = units.FeetInchesToMetres(300, 0)
+	assert.Contains(t, Text(r), "Набор высоты: 300 фт, сброс: 0 фт\n")
+}
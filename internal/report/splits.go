@@ -0,0 +1,76 @@
+package report
+
+import "time"
+
+// Split — отрезок (круг) тренировки.
+type Split struct {
+	Distance float64       // дистанция отрезка, км.
+	Duration time.Duration // время прохождения отрезка.
+}
+
+// Pace возвращает темп отрезка — время на километр.
+func (s Split) Pace() time.Duration {
+	if s.Distance <= 0 {
+		return 0
+	}
+	return time.Duration(float64(s.Duration) / s.Distance)
+}
+
+// FastestSplit возвращает номер (с нуля) отрезка с наименьшим темпом
+// или -1, если отрезков с дистанцией нет. Отрезки без дистанции (паузы)
+// не учитываются: темпа у них нет.
+func (r Result) FastestSplit() int {
+	return r.findSplit(func(a, b time.Duration) bool { return a < b })
+}
+
+// SlowestSplit возвращает номер (с нуля) отрезка с наибольшим темпом
+// или -1, если отрезков с дистанцией нет.
+func (r Result) SlowestSplit() int {
+	return r.findSplit(func(a, b time.Duration) bool { return a > b })
+}
+
+func (r Result) findSplit(better func(a, b time.Duration) bool) int {
+	best := -1
+	for i, s := range r.Splits {
+		if s.Distance <= 0 {
+			continue
+		}
+		if best < 0 || better(s.Pace(), r.Splits[best].Pace()) {
+			best = i
+		}
+	}
+	return best
+}
+
+// NegativeSplit сообщает, пройдена ли вторая половина дистанции быстрее
+// первой. Внутри отрезка темп считается постоянным, поэтому отрезок,
+// на который приходится середина дистанции, делится пропорционально.
+func (r Result) NegativeSplit() bool {
+	if len(r.Splits) < 2 {
+		return false
+	}
+
+	var distance float64
+	var total time.Duration
+	for _, s := range r.Splits {
+		distance += s.Distance
+		total += s.Duration
+	}
+	if distance <= 0 {
+		return false
+	}
+
+	half := distance / 2
+	var covered float64
+	var firstHalf time.Duration
+	for _, s := range r.Splits {
+		if covered+s.Distance >= half {
+			firstHalf += time.Duration(float64(s.Duration) * (half - covered) / s.Distance)
+			break
+		}
+		covered += s.Distance
+		firstHalf += s.Duration
+	}
+
+	return total-firstHalf < firstHalf
+}
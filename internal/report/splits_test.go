@@ -0,0 +1,152 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitPace(t *testing.T) {
+	assert.Equal(t, 5*time.Minute, Split{Distance: 0.5, Duration: 150 * time.Second}.Pace())
+	assert.Zero(t, Split{Duration: time.Minute}.Pace())
+}
+
+func TestSplitAnalysis(t *testing.T) {
+	tests := []struct {
+		name        string
+		splits      []Split
+		wantFastest int
+		wantSlowest int
+		wantNeg     bool
+	}{
+		{
+			name:        "нет кругов",
+			wantFastest: -1,
+			wantSlowest: -1,
+		},
+		{
+			name: "негативный сплит",
+			splits: []Split{
+				{Distance: 1, Duration: 5*time.Minute + 10*time.Second},
+				{Distance: 1, Duration: 5 * time.Minute},
+				{Distance: 1, Duration: 4*time.Minute + 50*time.Second},
+				{Distance: 1, Duration: 4*time.Minute + 40*time.Second},
+			},
+			wantFastest: 3,
+			wantSlowest: 0,
+			wantNeg:     true,
+		},
+		{
+			name: "положительный сплит с неполным кругом",
+			splits: []Split{
+				{Distance: 1, Duration: 4 * time.Minute},
+				{Distance: 1, Duration: 5 * time.Minute},
+				{Distance: 0.5, Duration: 3 * time.Minute},
+			},
+			wantFastest: 0,
+			wantSlowest: 2,
+		},
+		{
+			name: "середина внутри круга",
+			splits: []Split{
+				{Distance: 3, Duration: 18 * time.Minute},
+				{Distance: 1, Duration: 4 * time.Minute},
+			},
+			wantFastest: 1,
+			wantSlowest: 0,
+			wantNeg:     true,
+		},
+		{
+			name: "пауза без дистанции",
+			splits: []Split{
+				{Distance: 1, Duration: 5 * time.Minute},
+				{Duration: 2 * time.Minute},
+				{Distance: 1, Duration: 4 * time.Minute},
+			},
+			wantFastest: 2,
+			wantSlowest: 0,
+		},
+		{
+			name:        "только паузы",
+			splits:      []Split{{Duration: time.Minute}, {Duration: 2 * time.Minute}},
+			wantFastest: -1,
+			wantSlowest: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Result{Splits: tt.splits}
+			assert.Equal(t, tt.wantFastest, r.FastestSplit())
+			assert.Equal(t, tt.wantSlowest, r.SlowestSplit())
+			assert.Equal(t, tt.wantNeg, r.NegativeSplit())
+		})
+	}
+}
+
+func TestTextSplits(t *testing.T) {
+	r := Result{
+		Kind:     KindTraining,
+		Type:     "Бег",
+		Duration: 20 * time.Minute,
+		Distance: 4,
+		Speed:    12,
+		Calories: 300,
+		Pace:     5 * time.Minute,
+		Splits: []Split{
+			{Distance: 2, Duration: 10*time.Minute + 30*time.Second},
+			{Distance: 2, Duration: 9*time.Minute + 30*time.Second},
+		},
+	}
+
+	want := "Тип тренировки: Бег\nДлительность: 0.33 ч.\nДистанция: 4.00 км.\nСкорость: 12.00 км/ч\nСожгли калорий: 300.00\n" +
+		"Темп: 5:00 мин/км\n" +
+		"Круги:\n" +
+		"  1. 2.00 км за 10:30, темп 5:15 мин/км\n" +
+		"  2. 2.00 км за 9:30, темп 4:45 мин/км\n" +
+		"Самый быстрый круг: 2 (4:45 мин/км)\n" +
+		"Самый медленный круг: 1 (5:15 мин/км)\n" +
+		"Негативный сплит: да\n"
+	assert.Equal(t, want, Text(r))
+}
+
+func TestTextSplitsWithoutDistance(t *testing.T) {
+	r := Result{
+		Kind:     KindTraining,
+		Type:     "Бег",
+		Duration: 3 * time.Minute,
+		Splits:   []Split{{Duration: time.Minute}, {Duration: 2 * time.Minute}},
+	}
+
+	text := Text(r)
+	assert.NotContains(t, text, "Самый быстрый круг", "у кругов без дистанции нет темпа")
+	assert.NotContains(t, text, "Самый медленный круг")
+}
+
+func TestSplitsJSON(t *testing.T) {
+	r := Result{
+		Kind:     KindTraining,
+		Type:     "Бег",
+		Duration: 10 * time.Minute,
+		Distance: 2,
+		Pace:     5 * time.Minute,
+		Splits: []Split{
+			{Distance: 1, Duration: 5*time.Minute + 10*time.Second},
+			{Distance: 1, Duration: 4*time.Minute + 50*time.Second},
+		},
+	}
+
+	data, err := json.Marshal(r)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"training","type":"Бег","steps":0,"duration":"10m0s","duration_hours":0.16666666666666666,
+		"distance_km":2,"speed_kmh":0,"calories_kcal":0,"pace_s_per_km":300,
+		"splits":[{"distance_km":1,"duration":"5m10s","pace_s_per_km":310},{"distance_km":1,"duration":"4m50s","pace_s_per_km":290}],
+		"fastest_split":1,"slowest_split":0,"negative_split":true}`, string(data))
+
+	var decoded Result
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, r, decoded)
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"FINAL-PROJECT-5/internal/daysteps"
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+// Виды записей в запросах.
+const (
+	kindSteps     = "steps"
+	kindTrainings = "trainings"
+)
+
+// recordsRequest — тело запроса с одной записью (Record) или набором
+// записей (Records) в тех же форматах, что и во входных файлах.
+type recordsRequest struct {
+	Record  *string                `json:"record"`
+	Records []string               `json:"records"`
+	Profile *personaldata.Personal `json:"profile"`
+	// Mode — режим разбора: default (по умолчанию, record.Default),
+	// strict или lenient.
+	Mode string `json:"mode"`
+	// Implausible — действие для всех проверок правдоподобия: flag,
+	// reject или off; по умолчанию — как в правилах сервера.
+	Implausible string `json:"implausible"`
+	// Save — сохранить успешно обработанные записи в историю.
+	Save bool `json:"save"`
+}
+
+// recordResult — результат обработки одной записи набора.
+type recordResult struct {
+	Index  int            `json:"index"`
+	Input  string         `json:"input"`
+	Result *report.Result `json:"result,omitempty"`
+	Error  string         `json:"error,omitempty"`
+	recordError
+}
+
+// batchResponse — ответ на запрос с набором записей.
+type batchResponse struct {
+	Results   []recordResult `json:"results"`
+	Succeeded int            `json:"succeeded"`
+	Failed    int            `json:"failed"`
+	// Flagged — сколько успешно обработанных записей получили предупреждения.
+	Flagged int `json:"flagged"`
+}
+
+// singleResponse — ответ на запрос с одной записью.
+type singleResponse struct {
+	Result report.Result `json:"result"`
+}
+
+func (s *Server) handleRecords(kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req recordsRequest
+		if err := decodeBody(w, r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		switch {
+		case req.Record != nil && req.Records != nil, req.Record == nil && req.Records == nil:
+			writeError(w, http.StatusBadRequest, i18n.Errorf("запрос должен содержать либо record, либо records"))
+			return
+		case len(req.Records) > maxBatchSize:
+			writeError(w, http.StatusBadRequest, i18n.Errorf("слишком много записей в запросе: %d, допускается не больше %d", len(req.Records), maxBatchSize))
+			return
+		case req.Save && s.opts.Store == nil:
+			writeError(w, http.StatusBadRequest, i18n.Errorf("история не подключена, сохранение недоступно"))
+			return
+		}
+
+		mode, err := record.ParseMode(req.Mode)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		opts := record.ParseOptions{Mode: mode}
+
+		rules, err := s.rules(req.Implausible)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		person, err := s.profile(req.Profile)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err)
+			return
+		}
+
+		if req.Record != nil {
+			e, err := parseRecord(kind, *req.Record, person, opts, rules)
+			if err != nil {
+				writeError(w, http.StatusUnprocessableEntity, err)
+				return
+			}
+			if req.Save {
+				if err := s.opts.Store.Append(e); err != nil {
+					writeError(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
+			writeJSON(w, http.StatusOK, singleResponse{Result: e.Result})
+			return
+		}
+
+		resp := batchResponse{Results: make([]recordResult, 0, len(req.Records))}
+		var entries []history.Entry
+		for i, input := range req.Records {
+			res := recordResult{Index: i, Input: input}
+			e, err := parseRecord(kind, input, person, opts, rules)
+			if err != nil {
+				res.Error = err.Error()
+				res.recordError = newRecordError(err)
+				resp.Failed++
+			} else {
+				res.Result = &e.Result
+				resp.Succeeded++
+				if len(e.Result.Warnings) > 0 {
+					resp.Flagged++
+				}
+				entries = append(entries, e)
+			}
+			resp.Results = append(resp.Results, res)
+		}
+
+		if req.Save {
+			if err := s.opts.Store.Append(entries...); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// rules возвращает правила проверки правдоподобия сервера; непустое
+// action задаёт действие для всех проверок.
+func (s *Server) rules(action string) (*plausibility.Rules, error) {
+	rules := plausibility.DefaultRules()
+	if s.opts.Rules != nil {
+		rules = *s.opts.Rules
+	}
+	if action != "" {
+		act, err := plausibility.ParseAction(action)
+		if err != nil {
+			return nil, err
+		}
+		rules = rules.WithAction(act)
+	}
+	return &rules, nil
+}
+
+// profile возвращает профиль из запроса или профиль сервера.
+func (s *Server) profile(p *personaldata.Personal) (personaldata.Personal, error) {
+	if p == nil {
+		p = s.opts.Profile
+	}
+	if p == nil {
+		return personaldata.Personal{}, i18n.Errorf("профиль не задан: передайте его в поле profile")
+	}
+	if err := p.Validate(); err != nil {
+		var fe *personaldata.FieldError
+		if errors.As(err, &fe) {
+			return personaldata.Personal{}, i18n.Errorf("profile.%s: %w", fe.Field, err)
+		}
+		return personaldata.Personal{}, err
+	}
+	return *p, nil
+}
+
+// parseRecord разбирает запись и рассчитывает её показатели. Каждая запись
+// разбирается новым парсером: парсеры хранят состояние.
+func parseRecord(kind, input string, person personaldata.Personal, opts record.ParseOptions, rules *plausibility.Rules) (history.Entry, error) {
+	if kind == kindSteps {
+		ds := daysteps.DaySteps{Personal: person, ParseOptions: opts, Plausibility: rules}
+		if err := ds.Parse(input); err != nil {
+			return history.Entry{}, err
+		}
+		return history.FromDaySteps(input, ds)
+	}
+
+	t := trainings.Training{Personal: person, ParseOptions: opts, Plausibility: rules}
+	if err := t.Parse(input); err != nil {
+		return history.Entry{}, err
+	}
+	return history.FromTraining(input, t)
+}
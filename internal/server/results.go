@@ -0,0 +1,89 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+// resultsResponse — ответ /v1/results.
+type resultsResponse struct {
+	Entries []history.Entry `json:"entries"`
+}
+
+// handleResults возвращает записи из истории. Параметры запроса:
+// from и to — интервал (ГГГГ-ММ-ДД в UTC или RFC 3339, to не включается),
+// kind — steps или trainings, type — тип тренировки.
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Store == nil {
+		writeError(w, http.StatusNotFound, i18n.Errorf("история не подключена"))
+		return
+	}
+
+	q, err := parseQuery(r)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	entries, err := s.opts.Store.Query(q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if entries == nil {
+		entries = []history.Entry{}
+	}
+	writeJSON(w, http.StatusOK, resultsResponse{Entries: entries})
+}
+
+func parseQuery(r *http.Request) (history.Query, error) {
+	params := r.URL.Query()
+
+	var q history.Query
+	var err error
+	if v := params.Get("from"); v != "" {
+		if q.From, err = parseTime(v); err != nil {
+			return history.Query{}, invalidParam("from", v)
+		}
+	}
+	if v := params.Get("to"); v != "" {
+		if q.To, err = parseTime(v); err != nil {
+			return history.Query{}, invalidParam("to", v)
+		}
+	}
+
+	switch v := params.Get("kind"); v {
+	case "":
+	case kindSteps:
+		q.Kind = report.KindDaySteps
+	case kindTrainings:
+		q.Kind = report.KindTraining
+	default:
+		return history.Query{}, invalidParam("kind", v)
+	}
+
+	if v := params.Get("type"); v != "" {
+		activity, ok := trainings.Lookup(v)
+		if !ok {
+			return history.Query{}, i18n.Errorf("неизвестный тип тренировки: %s", v)
+		}
+		q.Activity = activity.Name
+	}
+	return q, nil
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func invalidParam(name, value string) error {
+	return i18n.Errorf("неверное значение параметра %s: %q", name, value)
+}
@@ -0,0 +1,125 @@
+// Package server предоставляет разбор записей и расчёт калорий по HTTP.
+//
+// Все ответы — JSON. Ошибки возвращаются как {"error": "сообщение"}
+// с кодом 400 для некорректного запроса, 422 для записей и параметров,
+// не прошедших проверку, и 404, если ресурс недоступен. Описание API
+// в формате OpenAPI доступно по адресу /openapi.json.
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/record"
+)
+
+//go:embed openapi.json
+var openAPI []byte
+
+// Ограничения на размер запроса.
+const (
+	maxBodySize  = 1 << 20
+	maxBatchSize = 1000
+)
+
+// Options — настройки сервера.
+type Options struct {
+	// Profile — профиль для записей, в запросе которых профиль не передан;
+	// если nil, профиль в запросе обязателен.
+	Profile *personaldata.Personal
+	// Store — история. Если nil, записи нельзя сохранять, а /v1/results
+	// отвечает 404.
+	Store *history.Store
+	// Rules — правила проверки правдоподобия записей; если nil,
+	// используются plausibility.DefaultRules.
+	Rules *plausibility.Rules
+}
+
+// Server — HTTP-обработчик API.
+type Server struct {
+	opts Options
+	mux  *http.ServeMux
+}
+
+// New создаёт сервер.
+func New(opts Options) *Server {
+	s := &Server{opts: opts, mux: http.NewServeMux()}
+
+	s.mux.HandleFunc("POST /v1/steps", s.handleRecords(kindSteps))
+	s.mux.HandleFunc("POST /v1/trainings", s.handleRecords(kindTrainings))
+	s.mux.HandleFunc("GET /v1/results", s.handleResults)
+	s.mux.HandleFunc("GET /v1/spentenergy", s.handleSpentEnergy)
+	s.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openAPI)
+	})
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// errorResponse — тело ответа с ошибкой. Для ошибок разбора записи
+// заполняются код ошибки, имя и номер поля; см. record.FieldError.
+// Для отклонённых неправдоподобных записей код — implausible, а поле —
+// название проверки; см. plausibility.Violation.
+type errorResponse struct {
+	Error string `json:"error"`
+	recordError
+}
+
+// codeImplausible — код ошибки для записей, отклонённых проверкой правдоподобия.
+const codeImplausible = "implausible"
+
+// recordError — подробности ошибки разбора записи.
+type recordError struct {
+	Code   string `json:"code,omitempty"`
+	Field  string `json:"field,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+func newRecordError(err error) recordError {
+	var v plausibility.Violation
+	if errors.As(err, &v) {
+		return recordError{Code: codeImplausible, Field: string(v.Check)}
+	}
+
+	re := recordError{Code: record.Code(err)}
+	var fe *record.FieldError
+	if errors.As(err, &fe) {
+		re.Field, re.Column = fe.Field, fe.Column
+	}
+	return re
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error(), recordError: newRecordError(err)})
+}
+
+// decodeBody разбирает тело запроса в v, запрещая неизвестные поля.
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return i18n.Errorf("слишком большой запрос: больше %d байт", tooLarge.Limit)
+		}
+		return i18n.Errorf("неверный формат запроса: %v", err)
+	}
+	return nil
+}
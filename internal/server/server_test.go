@@ -0,0 +1,268 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"FINAL-PROJECT-5/internal/history"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var person = personaldata.Personal{Name: "Витя", Weight: 84.6, Height: 1.87, Age: 30, Sex: personaldata.SexMale}
+
+func newServer(t *testing.T, withStore bool) *Server {
+	t.Helper()
+	opts := Options{Profile: &person}
+	if withStore {
+		s, err := history.Open(filepath.Join(t.TempDir(), "history.jsonl"))
+		require.NoError(t, err)
+		opts.Store = s
+	}
+	return New(opts)
+}
+
+// do выполняет запрос и разбирает JSON-ответ в v.
+func do(t *testing.T, s *Server, method, target, body string, v any) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if v != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v), rec.Body.String())
+	}
+	return rec.Code
+}
+
+func TestSingleRecord(t *testing.T) {
+	s := newServer(t, false)
+
+	var resp struct {
+		Result map[string]any `json:"result"`
+	}
+	code := do(t, s, http.MethodPost, "/v1/trainings", `{"record": "3456,Ходьба,3h00m,hr=110"}`, &resp)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "training", resp.Result["kind"])
+	assert.Equal(t, "Ходьба", resp.Result["type"])
+	assert.EqualValues(t, 110, resp.Result["heart_rate"])
+
+	var errResp errorResponse
+	code = do(t, s, http.MethodPost, "/v1/steps", `{"record": "678,abc"}`, &errResp)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, "неверный формат продолжительности", errResp.Error)
+	assert.Equal(t, "invalid_duration", errResp.Code)
+	assert.Equal(t, "duration", errResp.Field)
+	assert.Equal(t, 2, errResp.Column)
+}
+
+func TestBatch(t *testing.T) {
+	s := newServer(t, false)
+
+	var resp batchResponse
+	code := do(t, s, http.MethodPost, "/v1/steps", `{"records": ["678,0h50m", "", "-1,1h", "2024-05-01T08:00:00+03:00,6000,1h"]}`, &resp)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 2, resp.Succeeded)
+	assert.Equal(t, 2, resp.Failed)
+	require.Len(t, resp.Results, 4)
+	assert.NotNil(t, resp.Results[0].Result)
+	assert.Equal(t, 1, resp.Results[1].Index)
+	assert.Equal(t, "missing_fields", resp.Results[1].Code)
+	assert.Equal(t, "not_positive", resp.Results[2].Code)
+	assert.Equal(t, "steps", resp.Results[2].Field)
+	assert.Equal(t, 8, resp.Results[3].Result.Time.Hour())
+}
+
+func TestBadRequest(t *testing.T) {
+	s := newServer(t, false)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"не JSON", `record`, http.StatusBadRequest},
+		{"неизвестное поле", `{"record": "678,1h", "extra": 1}`, http.StatusBadRequest},
+		{"нет записей", `{}`, http.StatusBadRequest},
+		{"обе формы", `{"record": "678,1h", "records": ["678,1h"]}`, http.StatusBadRequest},
+		{"сохранение без истории", `{"record": "678,1h", "save": true}`, http.StatusBadRequest},
+		{"неверный профиль", `{"record": "678,1h", "profile": {"weight_kg": -1, "height_m": 1.8}}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp errorResponse
+			assert.Equal(t, tt.code, do(t, s, http.MethodPost, "/v1/steps", tt.body, &resp))
+			assert.NotEmpty(t, resp.Error)
+		})
+	}
+
+	var resp errorResponse
+	do(t, s, http.MethodPost, "/v1/steps", `{"record": "678,1h", "profile": {"weight_kg": -1, "height_m": 1.8}}`, &resp)
+	assert.Contains(t, resp.Error, "profile.weight_kg")
+
+	records := make([]string, maxBatchSize+1)
+	body, err := json.Marshal(recordsRequest{Records: records})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, do(t, s, http.MethodPost, "/v1/steps", string(body), nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, do(t, s, http.MethodGet, "/v1/steps", "", nil))
+}
+
+func TestMode(t *testing.T) {
+	s := newServer(t, false)
+
+	var errResp errorResponse
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, s, http.MethodPost, "/v1/trainings", `{"record": "6 000,бег,1h"}`, &errResp))
+	assert.Equal(t, "invalid_steps", errResp.Code)
+
+	var def singleResponse
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodPost, "/v1/trainings", `{"record": " 6000 , Бег , 1h "}`, &def), "по умолчанию поля тренировки очищаются от пробелов")
+	assert.Equal(t, 6000, def.Result.Steps)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, s, http.MethodPost, "/v1/trainings", `{"record": "6000, Бег ,1h", "mode": "strict"}`, &errResp))
+	assert.Equal(t, "invalid_type", errResp.Code)
+
+	var resp singleResponse
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodPost, "/v1/trainings", `{"record": "6 000,бег,1h", "mode": "lenient"}`, &resp))
+	assert.Equal(t, 6000, resp.Result.Steps)
+	assert.Equal(t, "Бег", resp.Result.Type)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, s, http.MethodPost, "/v1/steps", `{"record": "678,1h", "mode": "loose"}`, &errResp))
+}
+
+func TestImplausible(t *testing.T) {
+	s := newServer(t, false)
+
+	var errResp errorResponse
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, s, http.MethodPost, "/v1/steps", `{"record": "30000,1h"}`, &errResp))
+	assert.Equal(t, "implausible", errResp.Code)
+	assert.Equal(t, "cadence", errResp.Field)
+
+	var resp singleResponse
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodPost, "/v1/steps", `{"record": "30000,1h", "implausible": "flag"}`, &resp))
+	require.Len(t, resp.Result.Warnings, 2)
+	assert.Equal(t, plausibility.Cadence, resp.Result.Warnings[0].Check)
+	assert.Equal(t, plausibility.Speed, resp.Result.Warnings[1].Check)
+
+	var batch batchResponse
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodPost, "/v1/steps", `{"records": ["6000,1h", "14000,1h", "1000,25h"]}`, &batch))
+	assert.Equal(t, 2, batch.Succeeded)
+	assert.Equal(t, 1, batch.Failed)
+	assert.Equal(t, 1, batch.Flagged)
+	assert.Empty(t, batch.Results[0].Result.Warnings)
+	assert.Equal(t, "duration", batch.Results[2].Field)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, s, http.MethodPost, "/v1/steps", `{"record": "678,1h", "implausible": "warn"}`, &errResp))
+
+	rules := plausibility.DefaultRules().WithAction(plausibility.Off)
+	s = New(Options{Profile: &person, Rules: &rules})
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodPost, "/v1/steps", `{"record": "30000,1h"}`, &resp))
+	assert.Empty(t, resp.Result.Warnings)
+}
+
+func TestRequestProfile(t *testing.T) {
+	s := New(Options{})
+
+	var errResp errorResponse
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, s, http.MethodPost, "/v1/steps", `{"record": "678,1h"}`, &errResp))
+
+	var resp singleResponse
+	code := do(t, s, http.MethodPost, "/v1/steps", `{"record": "678,1h", "profile": {"weight_kg": 75, "height_m": 1.75}}`, &resp)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 678, resp.Result.Steps)
+}
+
+func TestSaveAndResults(t *testing.T) {
+	s := newServer(t, true)
+
+	code := do(t, s, http.MethodPost, "/v1/trainings", `{"records": ["2024-05-03T19:00:00+03:00,5000,Running,30m", "bad"], "save": true}`, nil)
+	require.Equal(t, http.StatusOK, code)
+	code = do(t, s, http.MethodPost, "/v1/steps", `{"record": "2024-06-01T08:00:00+03:00,6000,1h", "save": true}`, nil)
+	require.Equal(t, http.StatusOK, code)
+
+	var resp resultsResponse
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/v1/results", "", &resp))
+	assert.Len(t, resp.Entries, 2)
+
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/v1/results?kind=trainings&type=Бег", "", &resp))
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, "Бег", resp.Entries[0].Activity)
+
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/v1/results?from=2024-05-10&to=2024-07-01", "", &resp))
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, 6000, resp.Entries[0].Result.Steps)
+
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/v1/results?from=2025-01-01", "", &resp))
+	assert.NotNil(t, resp.Entries)
+	assert.Empty(t, resp.Entries)
+
+	var errResp errorResponse
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, s, http.MethodGet, "/v1/results?kind=swim", "", &errResp))
+	assert.Equal(t, http.StatusUnprocessableEntity, do(t, s, http.MethodGet, "/v1/results?from=вчера", "", &errResp))
+
+	assert.Equal(t, http.StatusNotFound, do(t, newServer(t, false), http.MethodGet, "/v1/results", "", &errResp))
+}
+
+func TestSpentEnergy(t *testing.T) {
+	s := newServer(t, false)
+
+	var resp spentEnergyResponse
+	code := do(t, s, http.MethodGet, "/v1/spentenergy?activity=running&steps=5000&duration=30m", "", &resp)
+	require.Equal(t, http.StatusOK, code)
+	assert.InDelta(t, 4.21, resp.Distance, 0.01)
+	assert.InDelta(t, 8.42, resp.Speed, 0.01)
+	assert.Positive(t, resp.Calories)
+	assert.InDelta(t, 3600/8.415, resp.Pace, 1)
+
+	var iso spentEnergyResponse
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/v1/spentenergy?activity=running&steps=5000&duration=PT30M", "", &iso))
+	assert.Equal(t, resp, iso, "продолжительность принимается в формате ISO 8601")
+
+	var met spentEnergyResponse
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/v1/spentenergy?activity=running&steps=5000&duration=30m&model=met&weight=70", "", &met))
+	assert.NotEqual(t, resp.Calories, met.Calories)
+
+	var hr spentEnergyResponse
+	require.Equal(t, http.StatusOK, do(t, s, http.MethodGet, "/v1/spentenergy?activity=running&steps=5000&duration=30m&heart_rate=150", "", &hr))
+	assert.NotEqual(t, resp.Calories, hr.Calories)
+
+	for _, query := range []string{
+		"activity=swimming&steps=5000&duration=30m",
+		"activity=running&duration=30m",
+		"activity=running&steps=0&duration=30m",
+		"activity=running&steps=5000",
+		"activity=running&steps=5000&duration=soon",
+		"activity=running&steps=5000&duration=30m&weight=heavy",
+		"activity=running&steps=5000&duration=30m&model=magic",
+		"activity=running&steps=5000&duration=30m&height=-1",
+		"activity=running&steps=5000&duration=30m&weight=NaN",
+		"activity=running&steps=5000&duration=30m&height=Inf",
+		"activity=running&steps=5000&duration=30m&heart_rate=NaN",
+		"activity=running&steps=5000&duration=30m&heart_rate=-150",
+		"activity=running&steps=5000&duration=30m&heart_rate=5",
+		"activity=running&steps=5000&duration=30m&heart_rate=900",
+		"activity=running&steps=5000&duration=30m&weight=0",
+		"activity=running&steps=5000&duration=30m&height=-1.8",
+	} {
+		var errResp errorResponse
+		assert.Equal(t, http.StatusUnprocessableEntity, do(t, s, http.MethodGet, "/v1/spentenergy?"+query, "", &errResp), query)
+		assert.NotEmpty(t, errResp.Error, query)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	require.Equal(t, http.StatusOK, do(t, newServer(t, false), http.MethodGet, "/openapi.json", "", &doc))
+	assert.NotEmpty(t, doc.OpenAPI)
+	for _, path := range []string{"/v1/steps", "/v1/trainings", "/v1/results", "/v1/spentenergy"} {
+		assert.Contains(t, doc.Paths, path)
+	}
+}
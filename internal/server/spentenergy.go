@@ -0,0 +1,144 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/spentenergy"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+// spentEnergyResponse — ответ /v1/spentenergy.
+type spentEnergyResponse struct {
+	Distance float64 `json:"distance_km"`
+	Speed    float64 `json:"speed_kmh"`
+	Calories float64 `json:"calories_kcal"`
+	Pace     float64 `json:"pace_s_per_km"`
+}
+
+// handleSpentEnergy рассчитывает дистанцию, скорость, темп и калории без
+// разбора записи. Обязательные параметры: activity (walking или running),
+// steps и duration. Вес и рост (weight, height) по умолчанию берутся из
+// профиля сервера; age, sex и heart_rate включают расчёт по пульсу,
+// model=met — расчёт по метаболическим эквивалентам.
+func (s *Server) handleSpentEnergy(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	in, person, estimator, err := s.spentEnergyParams(params)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	calories, err := estimator.Calories(in, person)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	speed := spentenergy.MeanSpeed(in.Steps, person.Height, in.Duration)
+	writeJSON(w, http.StatusOK, spentEnergyResponse{
+		Distance: spentenergy.Distance(in.Steps, person.Height),
+		Speed:    speed,
+		Calories: calories,
+		Pace:     spentenergy.Pace(speed).Seconds(),
+	})
+}
+
+func (s *Server) spentEnergyParams(params url.Values) (spentenergy.Input, personaldata.Personal, spentenergy.Estimator, error) {
+	var (
+		in     spentenergy.Input
+		person personaldata.Personal
+		err    error
+	)
+	if s.opts.Profile != nil {
+		person = *s.opts.Profile
+	}
+
+	in.Activity = params.Get("activity")
+	if _, ok := spentenergy.METTable[in.Activity]; !ok {
+		return in, person, nil, i18n.Errorf("неизвестная активность: %s", in.Activity)
+	}
+
+	if in.Steps, err = intParam(params, "steps"); err != nil {
+		return in, person, nil, err
+	}
+	if in.Steps <= 0 {
+		return in, person, nil, i18n.Errorf("количество шагов должно быть больше нуля")
+	}
+
+	durationStr := params.Get("duration")
+	if durationStr == "" {
+		return in, person, nil, missingParam("duration")
+	}
+	if in.Duration, err = record.ParseDuration(durationStr); err != nil {
+		return in, person, nil, i18n.Errorf("неверный формат продолжительности")
+	}
+	if in.Duration <= 0 {
+		return in, person, nil, i18n.Errorf("продолжительность должна быть больше нуля")
+	}
+
+	for name, dst := range map[string]*float64{"weight": &person.Weight, "height": &person.Height, "heart_rate": &in.HeartRate} {
+		v := params.Get(name)
+		if v == "" {
+			continue
+		}
+		// ParseFloat принимает NaN и Inf; «!(x > 0)» отсекает и NaN.
+		if *dst, err = strconv.ParseFloat(v, 64); err != nil || !(*dst > 0) || math.IsInf(*dst, 0) {
+			return in, person, nil, invalidParam(name, v)
+		}
+	}
+	// Пульс ограничен так же, как в записях о тренировках.
+	if v := params.Get("heart_rate"); v != "" && (in.HeartRate < trainings.MinHeartRate || in.HeartRate > trainings.MaxHeartRate) {
+		return in, person, nil, invalidParam("heart_rate", v)
+	}
+	if params.Has("age") {
+		if person.Age, err = intParam(params, "age"); err != nil {
+			return in, person, nil, err
+		}
+	}
+	if v := params.Get("sex"); v != "" {
+		if person.Sex, err = personaldata.ParseSex(v); err != nil {
+			return in, person, nil, err
+		}
+	}
+	if err := person.Validate(); err != nil {
+		return in, person, nil, err
+	}
+
+	var estimator spentenergy.Estimator
+	switch model := params.Get("model"); model {
+	case "", "speed":
+		estimator = spentenergy.ModelSpeed.Estimator()
+	case "met":
+		estimator = spentenergy.ModelMET.Estimator()
+	default:
+		return in, person, nil, invalidParam("model", model)
+	}
+	if in.HeartRate > 0 {
+		estimator = spentenergy.HeartRateEstimator{Fallback: estimator}
+	}
+
+	return in, person, estimator, nil
+}
+
+func intParam(params url.Values, name string) (int, error) {
+	v := params.Get(name)
+	if v == "" {
+		return 0, missingParam(name)
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, invalidParam(name, v)
+	}
+	return n, nil
+}
+
+func missingParam(name string) error {
+	return i18n.Errorf("не задан параметр %s", name)
+}
@@ -0,0 +1,90 @@
+package spentenergy
+
+import (
+	"time"
+
+	"FINAL-PROJECT-5/internal/personaldata"
+)
+
+// Input — данные активности, по которым рассчитываются калории.
+type Input struct {
+	Activity string // вид активности: Walking, Running или собственный ключ модели.
+	Steps    int
+	Duration time.Duration
+	// Distance — измеренная дистанция, км (например, по треку GPS);
+	// 0 — дистанция оценивается по шагам и росту.
+	Distance float64
+
+	HeartRate float64 // средний пульс, уд/мин; 0 — пульс не измерен.
+}
+
+// speed возвращает среднюю скорость по измеренной дистанции, км/ч.
+func (in Input) speed() float64 {
+	if in.Duration <= 0 {
+		return 0
+	}
+	return in.Distance / in.Duration.Hours()
+}
+
+// Estimator рассчитывает количество калорий, потраченных на активность.
+type Estimator interface {
+	Calories(in Input, p personaldata.Personal) (float64, error)
+}
+
+// EstimatorFunc позволяет использовать обычную функцию как Estimator.
+type EstimatorFunc func(in Input, p personaldata.Personal) (float64, error)
+
+func (f EstimatorFunc) Calories(in Input, p personaldata.Personal) (float64, error) {
+	return f(in, p)
+}
+
+// SpeedEstimator — расчёт по формулам на основе средней скорости
+// (WalkingSpentCalories и RunningSpentCalories).
+type SpeedEstimator struct{}
+
+func (SpeedEstimator) Calories(in Input, p personaldata.Personal) (float64, error) {
+	if in.Distance > 0 {
+		return SpeedSpentCalories(ModelSpeed, in.Activity, in.speed(), p.Weight, in.Duration)
+	}
+	return SpentCalories(ModelSpeed, in.Activity, in.Steps, p.Weight, p.Height, in.Duration)
+}
+
+// METEstimator — расчёт по модели метаболических эквивалентов.
+type METEstimator struct{}
+
+func (METEstimator) Calories(in Input, p personaldata.Personal) (float64, error) {
+	if in.Distance > 0 {
+		return SpeedSpentCalories(ModelMET, in.Activity, in.speed(), p.Weight, in.Duration)
+	}
+	return SpentCalories(ModelMET, in.Activity, in.Steps, p.Weight, p.Height, in.Duration)
+}
+
+// DefaultEstimator используется, когда модель расчёта не задана явно.
+var DefaultEstimator Estimator = SpeedEstimator{}
+
+// Estimator возвращает реализацию Estimator для модели.
+func (m Model) Estimator() Estimator {
+	if m == ModelMET {
+		return METEstimator{}
+	}
+	return SpeedEstimator{}
+}
+
+// HeartRateEstimator рассчитывает калории по среднему пульсу
+// (HeartRateSpentCalories), если пульс измерен, а в профиле указаны
+// возраст и пол. В остальных случаях расчёт передаётся Fallback
+// (если он nil — DefaultEstimator).
+type HeartRateEstimator struct {
+	Fallback Estimator
+}
+
+func (e HeartRateEstimator) Calories(in Input, p personaldata.Personal) (float64, error) {
+	if in.HeartRate > 0 && p.Age > 0 && p.Sex != "" {
+		return HeartRateSpentCalories(in.HeartRate, p.Weight, p.Age, p.Sex, in.Duration)
+	}
+
+	if e.Fallback != nil {
+		return e.Fallback.Calories(in, p)
+	}
+	return DefaultEstimator.Calories(in, p)
+}
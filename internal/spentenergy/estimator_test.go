@@ -0,0 +1,57 @@
+package spentenergy
+
+import (
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/personaldata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimators(t *testing.T) {
+	profile := personaldata.Personal{Weight: 75, Height: 1.75}
+	in := Input{Activity: Walking, Steps: 6000, Duration: time.Hour}
+
+	got, err := DefaultEstimator.Calories(in, profile)
+	require.NoError(t, err)
+	want, err := WalkingSpentCalories(in.Steps, profile.Weight, profile.Height, in.Duration)
+	require.NoError(t, err)
+	assert.Equal(t, want, got, "модель по умолчанию должна совпадать с текущими формулами")
+
+	got, err = ModelMET.Estimator().Calories(in, profile)
+	require.NoError(t, err)
+	assert.InDelta(t, 262.5, got, 1e-9)
+
+	_, err = SpeedEstimator{}.Calories(Input{Activity: "swimming", Steps: 1, Duration: time.Hour}, profile)
+	assert.Error(t, err)
+}
+
+func TestEstimatorsMeasuredDistance(t *testing.T) {
+	profile := personaldata.Personal{Weight: 75, Height: 1.75}
+	in := Input{Activity: Running, Steps: 6000, Duration: time.Hour, Distance: 10}
+
+	got, err := SpeedEstimator{}.Calories(in, profile)
+	require.NoError(t, err)
+	assert.InDelta(t, 750, got, 1e-9, "скорость считается по измеренной дистанции, а не по шагам")
+
+	got, err = METEstimator{}.Calories(in, profile)
+	require.NoError(t, err)
+	assert.InDelta(t, 735, got, 1e-9)
+
+	in.Steps = 0
+	got, err = SpeedEstimator{}.Calories(in, profile)
+	require.NoError(t, err, "шаги не нужны, если дистанция измерена")
+	assert.InDelta(t, 750, got, 1e-9)
+}
+
+func TestEstimatorFunc(t *testing.T) {
+	var e Estimator = EstimatorFunc(func(in Input, p personaldata.Personal) (float64, error) {
+		return float64(in.Steps) * p.Weight, nil
+	})
+
+	got, err := e.Calories(Input{Steps: 2}, personaldata.Personal{Weight: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, got)
+}
@@ -0,0 +1,57 @@
+package spentenergy
+
+import (
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+)
+
+// Коэффициенты формулы Keytel et al. (2005) для расчёта расхода энергии
+// по пульсу. Формула даёт кДж/мин, kJInKcal переводит их в ккал.
+const (
+	kJInKcal = 4.184
+
+	maleHRIntercept = -55.0969
+	maleHRCoef      = 0.6309
+	maleWeightCoef  = 0.1988
+	maleAgeCoef     = 0.2017
+
+	femaleHRIntercept = -20.4022
+	femaleHRCoef      = 0.4472
+	femaleWeightCoef  = -0.1263
+	femaleAgeCoef     = 0.074
+)
+
+// HeartRateSpentCalories рассчитывает калории по среднему пульсу
+// с учётом веса, возраста и пола (Keytel et al., 2005).
+func HeartRateSpentCalories(heartRate, weight float64, age int, sex personaldata.Sex, duration time.Duration) (float64, error) {
+	if heartRate <= 0 {
+		return 0, i18n.Errorf("пульс должен быть больше нуля")
+	}
+	if weight <= 0 {
+		return 0, i18n.Errorf("вес должен быть больше нуля")
+	}
+	if age <= 0 {
+		return 0, i18n.Errorf("возраст должен быть больше нуля")
+	}
+	if duration <= 0 {
+		return 0, i18n.Errorf("продолжительность должна быть больше нуля")
+	}
+
+	var perMinute float64
+	switch sex {
+	case personaldata.SexMale:
+		perMinute = maleHRIntercept + maleHRCoef*heartRate + maleWeightCoef*weight + maleAgeCoef*float64(age)
+	case personaldata.SexFemale:
+		perMinute = femaleHRIntercept + femaleHRCoef*heartRate + femaleWeightCoef*weight + femaleAgeCoef*float64(age)
+	default:
+		return 0, i18n.Errorf("для расчёта по пульсу нужен пол")
+	}
+
+	if perMinute <= 0 {
+		return 0, i18n.Errorf("пульс %.0f уд/мин слишком низкий для расчёта калорий", heartRate)
+	}
+
+	return perMinute / kJInKcal * duration.Minutes(), nil
+}
@@ -0,0 +1,64 @@
+package spentenergy
+
+import (
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/personaldata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartRateSpentCalories(t *testing.T) {
+	tests := []struct {
+		name      string
+		heartRate float64
+		weight    float64
+		age       int
+		sex       personaldata.Sex
+		duration  time.Duration
+		want      float64
+		wantErr   bool
+	}{
+		{name: "мужчина", heartRate: 150, weight: 75, age: 30, sex: personaldata.SexMale, duration: time.Hour, want: 867.5779},
+		{name: "женщина", heartRate: 150, weight: 60, age: 40, sex: personaldata.SexFemale, duration: 30 * time.Minute, want: 301.5760},
+		{name: "пол не указан", heartRate: 150, weight: 75, age: 30, duration: time.Hour, wantErr: true},
+		{name: "нулевой пульс", heartRate: 0, weight: 75, age: 30, sex: personaldata.SexMale, duration: time.Hour, wantErr: true},
+		{name: "слишком низкий пульс", heartRate: 40, weight: 50, age: 20, sex: personaldata.SexMale, duration: time.Hour, wantErr: true},
+		{name: "нулевая продолжительность", heartRate: 150, weight: 75, age: 30, sex: personaldata.SexMale, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := HeartRateSpentCalories(tt.heartRate, tt.weight, tt.age, tt.sex, tt.duration)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-3)
+		})
+	}
+}
+
+func TestHeartRateEstimatorFallback(t *testing.T) {
+	in := Input{Activity: Running, Steps: 6000, Duration: time.Hour, HeartRate: 150}
+	fallback := EstimatorFunc(func(Input, personaldata.Personal) (float64, error) {
+		return 1, nil
+	})
+	e := HeartRateEstimator{Fallback: fallback}
+
+	got, err := e.Calories(in, personaldata.Personal{Weight: 75, Height: 1.75, Age: 30, Sex: personaldata.SexMale})
+	require.NoError(t, err)
+	assert.InDelta(t, 867.5779, got, 1e-3, "при известных пульсе, возрасте и поле нужно считать по пульсу")
+
+	got, err = e.Calories(in, personaldata.Personal{Weight: 75, Height: 1.75})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, got, "без возраста и пола нужно использовать запасную модель")
+
+	in.HeartRate = 0
+	got, err = e.Calories(in, personaldata.Personal{Weight: 75, Height: 1.75, Age: 30, Sex: personaldata.SexMale})
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, got, "без пульса нужно использовать запасную модель")
+}
@@ -0,0 +1,158 @@
+package spentenergy
+
+import (
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// Виды активности, для которых известны формулы расчёта калорий.
+const (
+	Walking = "walking"
+	Running = "running"
+)
+
+// METBand — значение MET для скоростей от MinSpeed км/ч и выше
+// (до начала следующей полосы).
+type METBand struct {
+	MinSpeed float64 // нижняя граница скорости, км/ч.
+	MET      float64 // метаболический эквивалент.
+}
+
+// METTable — значения MET по видам активности в зависимости от скорости.
+// Значения взяты из Compendium of Physical Activities (2011), скорости
+// переведены из миль в час в км/ч. Полосы отсортированы по MinSpeed.
+var METTable = map[string][]METBand{
+	Walking: {
+		{MinSpeed: 0, MET: 2.0},   // 17151: медленнее 2.0 mph.
+		{MinSpeed: 3.2, MET: 2.8}, // 17152: 2.0 mph.
+		{MinSpeed: 4.0, MET: 3.0}, // 17170: 2.5 mph.
+		{MinSpeed: 4.5, MET: 3.5}, // 17190: 2.8–3.2 mph.
+		{MinSpeed: 5.6, MET: 4.3}, // 17200: 3.5 mph.
+		{MinSpeed: 6.4, MET: 5.0}, // 17220: 4.0 mph.
+		{MinSpeed: 7.2, MET: 7.0}, // 17230: 4.5 mph.
+		{MinSpeed: 8.0, MET: 8.3}, // 17231: 5.0 mph.
+	},
+	Running: {
+		{MinSpeed: 0, MET: 6.0},     // 12029: 4 mph.
+		{MinSpeed: 8.0, MET: 8.3},   // 12030: 5 mph.
+		{MinSpeed: 8.4, MET: 9.0},   // 12040: 5.2 mph.
+		{MinSpeed: 9.7, MET: 9.8},   // 12050: 6 mph.
+		{MinSpeed: 10.8, MET: 10.5}, // 12060: 6.7 mph.
+		{MinSpeed: 11.3, MET: 11.0}, // 12070: 7 mph.
+		{MinSpeed: 12.1, MET: 11.5}, // 12080: 7.5 mph.
+		{MinSpeed: 12.9, MET: 11.8}, // 12090: 8 mph.
+		{MinSpeed: 13.8, MET: 12.3}, // 12100: 8.6 mph.
+		{MinSpeed: 14.5, MET: 12.8}, // 12110: 9 mph.
+		{MinSpeed: 16.1, MET: 14.5}, // 12120: 10 mph.
+		{MinSpeed: 17.7, MET: 16.0}, // 12130: 11 mph.
+		{MinSpeed: 19.3, MET: 19.0}, // 12132: 12 mph.
+		{MinSpeed: 20.9, MET: 19.8}, // 12134: 13 mph.
+		{MinSpeed: 22.5, MET: 23.0}, // 12135: 14 mph.
+	},
+}
+
+// MET возвращает метаболический эквивалент активности при скорости speed км/ч.
+func MET(activity string, speed float64) (float64, error) {
+	bands, ok := METTable[activity]
+	if !ok || len(bands) == 0 {
+		return 0, i18n.Errorf("нет значений MET для активности: %s", activity)
+	}
+	if speed < 0 {
+		return 0, i18n.Errorf("скорость не может быть отрицательной")
+	}
+
+	met := bands[0].MET
+	for _, band := range bands[1:] {
+		if speed < band.MinSpeed {
+			break
+		}
+		met = band.MET
+	}
+
+	return met, nil
+}
+
+// METSpentCalories рассчитывает калории по модели MET:
+// MET × вес (кг) × продолжительность (ч).
+func METSpentCalories(activity string, steps int, weight, height float64, duration time.Duration) (float64, error) {
+	if steps <= 0 {
+		return 0, i18n.Errorf("количество шагов должно быть больше нуля")
+	}
+	if weight <= 0 {
+		return 0, i18n.Errorf("вес должен быть больше нуля")
+	}
+	if height <= 0 {
+		return 0, i18n.Errorf("рост должен быть больше нуля")
+	}
+	if duration <= 0 {
+		return 0, i18n.Errorf("продолжительность должна быть больше нуля")
+	}
+
+	met, err := MET(activity, MeanSpeed(steps, height, duration))
+	if err != nil {
+		return 0, err
+	}
+
+	return met * weight * duration.Hours(), nil
+}
+
+// SpeedSpentCalories рассчитывает калории по выбранной модели для
+// измеренной средней скорости speed км/ч — например, по дистанции трека
+// GPS, а не по количеству шагов и росту.
+func SpeedSpentCalories(model Model, activity string, speed, weight float64, duration time.Duration) (float64, error) {
+	if speed <= 0 {
+		return 0, i18n.Errorf("скорость должна быть больше нуля")
+	}
+	if weight <= 0 {
+		return 0, i18n.Errorf("вес должен быть больше нуля")
+	}
+	if duration <= 0 {
+		return 0, i18n.Errorf("продолжительность должна быть больше нуля")
+	}
+
+	switch model {
+	case ModelMET:
+		met, err := MET(activity, speed)
+		if err != nil {
+			return 0, err
+		}
+		return met * weight * duration.Hours(), nil
+	case ModelSpeed:
+		switch activity {
+		case Walking:
+			return walkingCalories(speed, weight, duration), nil
+		case Running:
+			return runningCalories(speed, weight, duration), nil
+		}
+		return 0, i18n.Errorf("неизвестная активность: %s", activity)
+	}
+
+	return 0, i18n.Errorf("неизвестная модель расчёта калорий: %d", model)
+}
+
+// Model — модель расчёта калорий.
+type Model int
+
+const (
+	ModelSpeed Model = iota // формулы на основе средней скорости (по умолчанию).
+	ModelMET                // модель метаболических эквивалентов.
+)
+
+// SpentCalories рассчитывает калории для активности по выбранной модели.
+func SpentCalories(model Model, activity string, steps int, weight, height float64, duration time.Duration) (float64, error) {
+	switch model {
+	case ModelMET:
+		return METSpentCalories(activity, steps, weight, height, duration)
+	case ModelSpeed:
+		switch activity {
+		case Walking:
+			return WalkingSpentCalories(steps, weight, height, duration)
+		case Running:
+			return RunningSpentCalories(steps, weight, height, duration)
+		}
+		return 0, i18n.Errorf("неизвестная активность: %s", activity)
+	}
+
+	return 0, i18n.Errorf("неизвестная модель расчёта калорий: %d", model)
+}
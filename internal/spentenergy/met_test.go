@@ -0,0 +1,100 @@
+package spentenergy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMET(t *testing.T) {
+	tests := []struct {
+		name     string
+		activity string
+		speed    float64
+		want     float64
+		wantErr  bool
+	}{
+		{name: "медленная ходьба", activity: Walking, speed: 2, want: 2.0},
+		{name: "ходьба на границе полосы", activity: Walking, speed: 5.6, want: 4.3},
+		{name: "быстрая ходьба", activity: Walking, speed: 9, want: 8.3},
+		{name: "бег 10 км/ч", activity: Running, speed: 10, want: 9.8},
+		{name: "очень быстрый бег", activity: Running, speed: 30, want: 23.0},
+		{name: "неизвестная активность", activity: "swimming", speed: 3, wantErr: true},
+		{name: "отрицательная скорость", activity: Walking, speed: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MET(tt.activity, tt.speed)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSpentCaloriesModels(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    Model
+		activity string
+		steps    int
+		want     float64
+		wantErr  bool
+	}{
+		{name: "скоростная модель - ходьба", model: ModelSpeed, activity: Walking, steps: 6000, want: 177.1875},
+		{name: "скоростная модель - бег", model: ModelSpeed, activity: Running, steps: 6000, want: 354.375},
+		{name: "MET - ходьба", model: ModelMET, activity: Walking, steps: 6000, want: 262.5},
+		{name: "MET - бег", model: ModelMET, activity: Running, steps: 20000, want: 960},
+		{name: "MET - нулевые шаги", model: ModelMET, activity: Running, steps: 0, wantErr: true},
+		{name: "неизвестная активность", model: ModelSpeed, activity: "swimming", steps: 6000, wantErr: true},
+		{name: "неизвестная модель", model: Model(42), activity: Walking, steps: 6000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SpentCalories(tt.model, tt.activity, tt.steps, 75, 1.75, time.Hour)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+func TestSpeedSpentCalories(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    Model
+		activity string
+		speed    float64
+		want     float64
+		wantErr  bool
+	}{
+		{name: "скоростная модель - ходьба", model: ModelSpeed, activity: Walking, speed: 5, want: 187.5},
+		{name: "скоростная модель - бег", model: ModelSpeed, activity: Running, speed: 10, want: 750},
+		{name: "MET - бег", model: ModelMET, activity: Running, speed: 10, want: 735},
+		{name: "нулевая скорость", model: ModelSpeed, activity: Running, speed: 0, wantErr: true},
+		{name: "неизвестная активность", model: ModelMET, activity: "swimming", speed: 10, wantErr: true},
+		{name: "неизвестная модель", model: Model(42), activity: Walking, speed: 5, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SpeedSpentCalories(tt.model, tt.activity, tt.speed, 75, time.Hour)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
@@ -1,8 +1,9 @@
 package spentenergy
 
 import (
-	"fmt"
 	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
 )
 
 // Основные константы, необходимые для расчетов.
@@ -15,44 +16,44 @@ const (
 
 func WalkingSpentCalories(steps int, weight, height float64, duration time.Duration) (float64, error) {
 	if steps <= 0 {
-		return 0, fmt.Errorf("количество шагов должно быть больше нуля")
+		return 0, i18n.Errorf("количество шагов должно быть больше нуля")
 	}
 	if weight <= 0 {
-		return 0, fmt.Errorf("вес должен быть больше нуля")
+		return 0, i18n.Errorf("вес должен быть больше нуля")
 	}
 	if height <= 0 {
-		return 0, fmt.Errorf("рост должен быть больше нуля")
+		return 0, i18n.Errorf("рост должен быть больше нуля")
 	}
 	if duration <= 0 {
-		return 0, fmt.Errorf("продолжительность должна быть больше нуля")
+		return 0, i18n.Errorf("продолжительность должна быть больше нуля")
 	}
 
-	averageSpeed := MeanSpeed(steps, height, duration)
-
-	calories := (weight * averageSpeed * duration.Minutes()) / minInH
-	calories = calories * walkingCaloriesCoefficient
-	return calories, nil
+	return walkingCalories(MeanSpeed(steps, height, duration), weight, duration), nil
 }
 
 func RunningSpentCalories(steps int, weight, height float64, duration time.Duration) (float64, error) {
 	if steps <= 0 {
-		return 0, fmt.Errorf("количество шагов должно быть больше нуля")
+		return 0, i18n.Errorf("количество шагов должно быть больше нуля")
 	}
 	if weight <= 0 {
-		return 0, fmt.Errorf("вес должен быть больше нуля")
+		return 0, i18n.Errorf("вес должен быть больше нуля")
 	}
 	if height <= 0 {
-		return 0, fmt.Errorf("рост должен быть больше нуля")
+		return 0, i18n.Errorf("рост должен быть больше нуля")
 	}
 	if duration <= 0 {
-		return 0, fmt.Errorf("продолжительность должна быть больше нуля")
+		return 0, i18n.Errorf("продолжительность должна быть больше нуля")
 	}
 
-	averageSpeed := MeanSpeed(steps, height, duration)
+	return runningCalories(MeanSpeed(steps, height, duration), weight, duration), nil
+}
 
-	calories := (weight * averageSpeed * duration.Minutes()) / minInH
+func walkingCalories(speed, weight float64, duration time.Duration) float64 {
+	return runningCalories(speed, weight, duration) * walkingCaloriesCoefficient
+}
 
-	return calories, nil
+func runningCalories(speed, weight float64, duration time.Duration) float64 {
+	return (weight * speed * duration.Minutes()) / minInH
 }
 
 func MeanSpeed(steps int, height float64, duration time.Duration) float64 {
@@ -76,3 +77,12 @@ func Distance(steps int, height float64) float64 {
 	distance = distance / mInKm
 	return distance
 }
+
+// Pace возвращает темп — время на один километр — для средней скорости
+// speed км/ч (см. MeanSpeed). Для нулевой скорости темп равен нулю.
+func Pace(speed float64) time.Duration {
+	if speed <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Hour) / speed)
+}
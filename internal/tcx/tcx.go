@@ -0,0 +1,271 @@
+// Package tcx читает активности в формате Garmin Training Center (TCX)
+// и превращает их в записи о тренировках.
+//
+// Из кругов (Lap) берутся время по таймеру, дистанция, калории и средний
+// пульс, измеренные устройством; из точек трека (Trackpoint) — пульс
+// и каденс, если в круге их нет, а также набор и сброс высоты и общее
+// время с остановками. Каденс бега в TCX записывается для одной ноги
+// (RunCadence), поэтому шагов в минуту вдвое больше; каденс велосипеда
+// (Cadence, об/мин) на количество шагов не влияет.
+//
+// Вид спорта сопоставляется с типом тренировки по таблице: Running —
+// бег, Walking — ходьба. Для остальных видов (Biking, Other) в реестре
+// нет подходящего типа, и тип тренировки нужно задать явно.
+package tcx
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"FINAL-PROJECT-5/internal/gpx"
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+)
+
+// Activity — активность: вид спорта из атрибута Sport, время начала
+// и круги.
+type Activity struct {
+	Sport string
+	Start time.Time
+	Laps  []Lap
+}
+
+// Lap — круг активности.
+type Lap struct {
+	Start     time.Time     // время начала круга.
+	Duration  time.Duration // время по таймеру без пауз.
+	Distance  float64       // дистанция, км.
+	Calories  float64       // калории по данным устройства, ккал.
+	HeartRate int           // средний пульс, уд/мин; 0 — не измерен.
+	Cadence   int           // средний каденс бега, шагов в минуту; 0 — не измерен.
+	Steps     int           // количество шагов по данным устройства; 0 — не сообщалось.
+	Points    []gpx.Point   // точки трека с координатами.
+}
+
+// tcxFile — разметка TCX. Элементы сопоставляются по локальному имени,
+// поэтому расширения подходят с любым префиксом пространства имён.
+type tcxFile struct {
+	XMLName    xml.Name      `xml:"TrainingCenterDatabase"`
+	Activities []tcxActivity `xml:"Activities>Activity"`
+}
+
+type tcxActivity struct {
+	Sport string   `xml:"Sport,attr"`
+	ID    string   `xml:"Id"`
+	Laps  []tcxLap `xml:"Lap"`
+}
+
+type tcxLap struct {
+	StartTime        string          `xml:"StartTime,attr"`
+	TotalTimeSeconds float64         `xml:"TotalTimeSeconds"`
+	DistanceMeters   float64         `xml:"DistanceMeters"`
+	Calories         float64         `xml:"Calories"`
+	AverageHeartRate int             `xml:"AverageHeartRateBpm>Value"`
+	AvgRunCadence    int             `xml:"Extensions>LX>AvgRunCadence"`
+	Steps            int             `xml:"Extensions>LX>Steps"`
+	Trackpoints      []tcxTrackpoint `xml:"Track>Trackpoint"`
+}
+
+type tcxTrackpoint struct {
+	Time       string   `xml:"Time"`
+	Lat        *float64 `xml:"Position>LatitudeDegrees"`
+	Lon        *float64 `xml:"Position>LongitudeDegrees"`
+	Altitude   *float64 `xml:"AltitudeMeters"`
+	HeartRate  int      `xml:"HeartRateBpm>Value"`
+	RunCadence int      `xml:"Extensions>TPX>RunCadence"`
+}
+
+// Parse читает активности из документа TCX.
+func Parse(r io.Reader) ([]Activity, error) {
+	var doc tcxFile
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, i18n.Errorf("ошибка чтения TCX: %v", err)
+	}
+	if len(doc.Activities) == 0 {
+		return nil, i18n.Errorf("в файле TCX нет активностей")
+	}
+
+	activities := make([]Activity, 0, len(doc.Activities))
+	for _, act := range doc.Activities {
+		a := Activity{Sport: strings.TrimSpace(act.Sport)}
+		if id := strings.TrimSpace(act.ID); id != "" {
+			t, err := parseTime(id)
+			if err != nil {
+				return nil, err
+			}
+			a.Start = t
+		}
+		for _, l := range act.Laps {
+			lap, err := l.lap()
+			if err != nil {
+				return nil, err
+			}
+			a.Laps = append(a.Laps, lap)
+		}
+		if a.Start.IsZero() && len(a.Laps) > 0 {
+			a.Start = a.Laps[0].Start
+		}
+		activities = append(activities, a)
+	}
+	return activities, nil
+}
+
+func (l tcxLap) lap() (Lap, error) {
+	if l.TotalTimeSeconds < 0 || l.DistanceMeters < 0 || l.Calories < 0 {
+		return Lap{}, i18n.Errorf("отрицательные показатели круга")
+	}
+
+	lap := Lap{
+		Duration:  time.Duration(math.Round(l.TotalTimeSeconds * float64(time.Second))),
+		Distance:  l.DistanceMeters / 1000,
+		Calories:  l.Calories,
+		HeartRate: l.AverageHeartRate,
+		Cadence:   2 * l.AvgRunCadence,
+		Steps:     l.Steps,
+	}
+	if s := strings.TrimSpace(l.StartTime); s != "" {
+		t, err := parseTime(s)
+		if err != nil {
+			return Lap{}, err
+		}
+		lap.Start = t
+	}
+
+	var hrSum, hrCount, cadSum, cadCount int
+	for _, tp := range l.Trackpoints {
+		if tp.HeartRate > 0 {
+			hrSum += tp.HeartRate
+			hrCount++
+		}
+		if tp.RunCadence > 0 {
+			cadSum += 2 * tp.RunCadence
+			cadCount++
+		}
+		if tp.Lat == nil || tp.Lon == nil {
+			continue
+		}
+
+		p := gpx.Point{Lat: *tp.Lat, Lon: *tp.Lon, HeartRate: tp.HeartRate}
+		if tp.Altitude != nil {
+			p.Elevation, p.HasElevation = *tp.Altitude, true
+		}
+		if s := strings.TrimSpace(tp.Time); s != "" {
+			t, err := parseTime(s)
+			if err != nil {
+				return Lap{}, err
+			}
+			p.Time = t
+		}
+		lap.Points = append(lap.Points, p)
+	}
+
+	if lap.HeartRate == 0 && hrCount > 0 {
+		lap.HeartRate = int(math.Round(float64(hrSum) / float64(hrCount)))
+	}
+	if lap.Cadence == 0 && cadCount > 0 {
+		lap.Cadence = int(math.Round(float64(cadSum) / float64(cadCount)))
+	}
+	return lap, nil
+}
+
+// sports сопоставляет вид спорта из атрибута Sport с типом тренировки
+// в реестре. Схема TCX знает Running, Biking и Other; Walking записывают
+// некоторые устройства.
+var sports = map[string]string{
+	"Running": trainings.Running,
+	"Walking": trainings.Walking,
+}
+
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, i18n.Errorf("неверный формат времени в TCX: %q", s)
+	}
+	return t, nil
+}
+
+// Training возвращает тренировку по активности. Тип тренировки —
+// activity (ищется в trainings.DefaultRegistry без учёта регистра),
+// а если он пуст — тип по виду спорта из sports; для вида спорта,
+// которого нет в sports, возвращается ошибка. Дистанция и калории
+// берутся из данных устройства; если дистанции в кругах нет, она
+// считается по координатам точек. Шаги берутся из данных устройства
+// или считаются по каденсу. Несколько кругов с дистанцией становятся
+// отрезками тренировки.
+func (a Activity) Training(activity string) (trainings.Training, error) {
+	if activity == "" {
+		if a.Sport == "" {
+			return trainings.Training{}, i18n.Errorf("в активности не указан вид спорта")
+		}
+		var ok bool
+		if activity, ok = sports[a.Sport]; !ok {
+			return trainings.Training{}, i18n.Errorf("для вида спорта %s нет типа тренировки: укажите тип явно (-type)", a.Sport)
+		}
+	}
+	act, ok := trainings.DefaultRegistry().LookupFold(activity)
+	if !ok {
+		return trainings.Training{}, i18n.Errorf("неизвестный тип тренировки: %s", activity)
+	}
+
+	t := trainings.Training{Time: a.Start, TrainingType: act.Name}
+	var track gpx.Track
+	var hrWeighted float64
+	var hrDuration time.Duration
+	end := a.Start
+	for _, lap := range a.Laps {
+		t.Duration += lap.Duration
+		t.Distance += lap.Distance
+		t.DeviceCalories += lap.Calories
+
+		steps := lap.Steps
+		if steps == 0 {
+			steps = int(math.Round(float64(lap.Cadence) * lap.Duration.Minutes()))
+		}
+		t.Steps += steps
+
+		if lap.HeartRate > 0 {
+			hrWeighted += float64(lap.HeartRate) * lap.Duration.Seconds()
+			hrDuration += lap.Duration
+		}
+		if lapEnd := lap.Start.Add(lap.Duration); !lap.Start.IsZero() && lapEnd.After(end) {
+			end = lapEnd
+		}
+		if len(lap.Points) > 0 {
+			track.Segments = append(track.Segments, lap.Points)
+		}
+	}
+	if t.Duration <= 0 {
+		return trainings.Training{}, i18n.Errorf("в активности нет кругов с продолжительностью")
+	}
+
+	stats := track.Stats()
+	if t.Distance == 0 {
+		t.Distance = stats.Distance
+	}
+	if hrDuration > 0 {
+		t.HeartRate = int(math.Round(hrWeighted / hrDuration.Seconds()))
+	}
+	if !a.Start.IsZero() {
+		if stats.Start.Add(stats.Elapsed).After(end) {
+			end = stats.Start.Add(stats.Elapsed)
+		}
+		t.Elapsed = end.Sub(a.Start)
+	}
+	t.ElevationGain, t.ElevationLoss = stats.ElevationGain, stats.ElevationLoss
+
+	// Круги без дистанции — паузы и автокруги на месте; у них нет темпа.
+	var laps []report.Split
+	for _, lap := range a.Laps {
+		if lap.Distance > 0 {
+			laps = append(laps, report.Split{Distance: lap.Distance, Duration: lap.Duration})
+		}
+	}
+	if len(laps) > 1 {
+		t.Laps = laps
+	}
+	return t, nil
+}
@@ -0,0 +1,199 @@
+package tcx
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/gpx"
+	"FINAL-PROJECT-5/internal/report"
+	"FINAL-PROJECT-5/internal/trainings"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sample — пробежка из двух кругов по километру с минутной паузой между
+// ними и велотренировка без дистанции в круге. В первом круге средний
+// пульс и каденс даны в самом круге, во втором — только в точках трека.
+const sample = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+    xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2">
+  <Activities>
+    <Activity Sport="Running">
+      <Id>2024-05-01T07:00:00Z</Id>
+      <Lap StartTime="2024-05-01T07:00:00Z">
+        <TotalTimeSeconds>300</TotalTimeSeconds>
+        <DistanceMeters>1000</DistanceMeters>
+        <Calories>80</Calories>
+        <AverageHeartRateBpm><Value>140</Value></AverageHeartRateBpm>
+        <Track>
+          <Trackpoint>
+            <Time>2024-05-01T07:00:00Z</Time>
+            <Position><LatitudeDegrees>55.000</LatitudeDegrees><LongitudeDegrees>37.000</LongitudeDegrees></Position>
+            <AltitudeMeters>100</AltitudeMeters>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2024-05-01T07:05:00Z</Time>
+            <Position><LatitudeDegrees>55.009</LatitudeDegrees><LongitudeDegrees>37.000</LongitudeDegrees></Position>
+            <AltitudeMeters>103</AltitudeMeters>
+          </Trackpoint>
+        </Track>
+        <Extensions><ns3:LX><ns3:AvgRunCadence>85</ns3:AvgRunCadence></ns3:LX></Extensions>
+      </Lap>
+      <Lap StartTime="2024-05-01T07:06:00Z">
+        <TotalTimeSeconds>240</TotalTimeSeconds>
+        <DistanceMeters>1000</DistanceMeters>
+        <Calories>70</Calories>
+        <Track>
+          <Trackpoint>
+            <Time>2024-05-01T07:06:00Z</Time>
+            <Position><LatitudeDegrees>55.009</LatitudeDegrees><LongitudeDegrees>37.000</LongitudeDegrees></Position>
+            <AltitudeMeters>103</AltitudeMeters>
+            <HeartRateBpm><Value>150</Value></HeartRateBpm>
+            <Extensions><ns3:TPX><ns3:RunCadence>88</ns3:RunCadence></ns3:TPX></Extensions>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2024-05-01T07:10:00Z</Time>
+            <Position><LatitudeDegrees>55.018</LatitudeDegrees><LongitudeDegrees>37.000</LongitudeDegrees></Position>
+            <AltitudeMeters>99</AltitudeMeters>
+            <HeartRateBpm><Value>160</Value></HeartRateBpm>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2024-05-01T07:10:00Z</Time>
+            <HeartRateBpm><Value>155</Value></HeartRateBpm>
+          </Trackpoint>
+        </Track>
+        <Extensions><ns3:LX><ns3:Steps>700</ns3:Steps></ns3:LX></Extensions>
+      </Lap>
+    </Activity>
+    <Activity Sport="Biking">
+      <Id>2024-05-02T07:00:00Z</Id>
+      <Lap StartTime="2024-05-02T07:00:00Z">
+        <TotalTimeSeconds>120</TotalTimeSeconds>
+        <Track>
+          <Trackpoint>
+            <Time>2024-05-02T07:00:00Z</Time>
+            <Position><LatitudeDegrees>55.000</LatitudeDegrees><LongitudeDegrees>37.000</LongitudeDegrees></Position>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2024-05-02T07:02:00Z</Time>
+            <Position><LatitudeDegrees>55.010</LatitudeDegrees><LongitudeDegrees>37.000</LongitudeDegrees></Position>
+          </Trackpoint>
+        </Track>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+// degreeLat — длина одного градуса меридиана, км.
+const degreeLat = gpx.EarthRadius * math.Pi / 180
+
+func TestParse(t *testing.T) {
+	activities, err := Parse(strings.NewReader(sample))
+	require.NoError(t, err)
+	require.Len(t, activities, 2)
+
+	run := activities[0]
+	assert.Equal(t, "Running", run.Sport)
+	assert.Equal(t, time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC), run.Start)
+	require.Len(t, run.Laps, 2)
+
+	first := run.Laps[0]
+	assert.Equal(t, 5*time.Minute, first.Duration)
+	assert.Equal(t, 1.0, first.Distance)
+	assert.Equal(t, 80.0, first.Calories)
+	assert.Equal(t, 140, first.HeartRate)
+	assert.Equal(t, 170, first.Cadence, "каденс одной ноги удваивается")
+	require.Len(t, first.Points, 2)
+	assert.Equal(t, gpx.Point{Lat: 55, Lon: 37, Elevation: 100, HasElevation: true,
+		Time: time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)}, first.Points[0])
+
+	second := run.Laps[1]
+	assert.Equal(t, 155, second.HeartRate, "средний пульс по точкам, включая точки без координат")
+	assert.Equal(t, 176, second.Cadence)
+	assert.Equal(t, 700, second.Steps)
+	assert.Len(t, second.Points, 2, "точки без координат в трек не входят")
+
+	assert.Zero(t, activities[1].Laps[0].Distance)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"не XML", "activity"},
+		{"нет активностей", `<TrainingCenterDatabase><Activities/></TrainingCenterDatabase>`},
+		{"неверное время", `<TrainingCenterDatabase><Activities><Activity Sport="Running"><Id>вчера</Id></Activity></Activities></TrainingCenterDatabase>`},
+		{"отрицательная дистанция", `<TrainingCenterDatabase><Activities><Activity Sport="Running"><Lap><DistanceMeters>-1</DistanceMeters></Lap></Activity></Activities></TrainingCenterDatabase>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTraining(t *testing.T) {
+	activities, err := Parse(strings.NewReader(sample))
+	require.NoError(t, err)
+
+	tr, err := activities[0].Training("")
+	require.NoError(t, err)
+	assert.Equal(t, trainings.Running, tr.TrainingType, "вид спорта сопоставляется по таблице")
+	assert.Equal(t, time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC), tr.Time)
+	assert.Equal(t, 9*time.Minute, tr.Duration)
+	assert.Equal(t, 10*time.Minute, tr.Elapsed, "пауза между кругами входит в общее время")
+	assert.Equal(t, 2.0, tr.Distance, "дистанция устройства, а не по координатам")
+	assert.Equal(t, 150.0, tr.DeviceCalories)
+	assert.Equal(t, 850+700, tr.Steps, "шаги первого круга считаются по каденсу")
+	assert.Equal(t, 147, tr.HeartRate, "пульс кругов взвешивается по времени")
+	assert.Equal(t, 3.0, tr.ElevationGain)
+	assert.Equal(t, 4.0, tr.ElevationLoss)
+	assert.Equal(t, []report.Split{{Distance: 1, Duration: 5 * time.Minute}, {Distance: 1, Duration: 4 * time.Minute}}, tr.Laps)
+
+	tr, err = activities[1].Training("бег")
+	require.NoError(t, err)
+	assert.Equal(t, trainings.Running, tr.TrainingType, "явный тип важнее вида спорта")
+
+	_, err = activities[1].Training("")
+	assert.ErrorContains(t, err, "Biking", "велосипеда нет в реестре — нужен явный тип")
+
+	tr, err = activities[1].Training("Ходьба")
+	require.NoError(t, err)
+	assert.Equal(t, trainings.Walking, tr.TrainingType)
+	assert.InDelta(t, 0.01*degreeLat, tr.Distance, 1e-9, "без дистанции в круге она считается по координатам")
+	assert.Empty(t, tr.Laps, "единственный круг не становится отрезком")
+	assert.Zero(t, tr.DeviceCalories)
+
+	tr, err = Activity{Sport: "Walking", Laps: activities[0].Laps}.Training("")
+	require.NoError(t, err)
+	assert.Equal(t, trainings.Walking, tr.TrainingType)
+
+	for _, sport := range []string{"Other", "running"} {
+		_, err = Activity{Sport: sport, Laps: activities[0].Laps}.Training("")
+		assert.Error(t, err, sport)
+	}
+
+	pause := Lap{Duration: time.Minute}
+	tr, err = Activity{Sport: "Running", Laps: append([]Lap{pause}, activities[0].Laps...)}.Training("")
+	require.NoError(t, err)
+	assert.Equal(t, []report.Split{{Distance: 1, Duration: 5 * time.Minute}, {Distance: 1, Duration: 4 * time.Minute}}, tr.Laps,
+		"круг без дистанции не становится отрезком")
+
+	tr, err = Activity{Sport: "Running", Laps: []Lap{activities[0].Laps[0], pause}}.Training("")
+	require.NoError(t, err)
+	assert.Empty(t, tr.Laps, "остался один круг с дистанцией")
+
+	_, err = Activity{Sport: "Running"}.Training("")
+	assert.Error(t, err, "нет кругов")
+
+	_, err = Activity{Sport: "Running", Laps: activities[0].Laps}.Training("плавание")
+	assert.Error(t, err, "неизвестный явный тип")
+
+	_, err = Activity{Laps: activities[0].Laps}.Training("")
+	assert.Error(t, err, "вид спорта не задан")
+}
@@ -0,0 +1,166 @@
+package trainings
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/spentenergy"
+)
+
+// Названия встроенных типов тренировок.
+const (
+	Running = "Бег"
+	Walking = "Ходьба"
+)
+
+// CaloriesFunc рассчитывает количество калорий, потраченных на тренировке.
+type CaloriesFunc func(steps int, weight, height float64, duration time.Duration) (float64, error)
+
+// Activity описывает тип тренировки.
+type Activity struct {
+	Name        string       // каноническое название, например "Бег".
+	DisplayName string       // название для отчёта; если пусто, используется перевод Name на текущий язык.
+	Aliases     []string     // дополнительные названия, по которым тип распознаётся во входных данных.
+	Kind        string       // вид активности для spentenergy.Estimator, например spentenergy.Running.
+	Calories    CaloriesFunc // функция расчёта калорий; используется, если Kind не задан.
+	ShowPace    bool         // выводить в отчёте темп (время на единицу дистанции).
+}
+
+// Title возвращает название типа тренировки для отчёта.
+func (a Activity) Title() string {
+	if a.DisplayName != "" {
+		return a.DisplayName
+	}
+	return i18n.T(a.Name)
+}
+
+// Registry — набор зарегистрированных типов тренировок. Безопасен
+// для одновременного использования из нескольких горутин.
+type Registry struct {
+	mu         sync.RWMutex
+	activities map[string]*Activity // название или псевдоним → тип.
+}
+
+func NewRegistry() *Registry {
+	return &Registry{activities: make(map[string]*Activity)}
+}
+
+// Register добавляет тип тренировки. Название и псевдонимы не должны
+// совпадать с уже зарегистрированными.
+func (r *Registry) Register(a Activity) error {
+	a.Name = strings.TrimSpace(a.Name)
+	if a.Name == "" {
+		return i18n.Errorf("название типа тренировки не может быть пустым")
+	}
+	if a.Kind == "" && a.Calories == nil {
+		return i18n.Errorf("для типа тренировки %s не задан вид активности или функция расчёта калорий", a.Name)
+	}
+
+	names := []string{a.Name}
+	for _, alias := range a.Aliases {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			return i18n.Errorf("псевдоним типа тренировки %s не может быть пустым", a.Name)
+		}
+		names = append(names, alias)
+	}
+	a.Aliases = append([]string(nil), names[1:]...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, name := range names {
+		if _, ok := r.activities[name]; ok {
+			return i18n.Errorf("тип тренировки %s уже зарегистрирован", name)
+		}
+	}
+
+	for _, name := range names {
+		r.activities[name] = &a
+	}
+
+	return nil
+}
+
+// Lookup ищет тип тренировки по названию или псевдониму.
+func (r *Registry) Lookup(name string) (Activity, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.activities[strings.TrimSpace(name)]
+	if !ok {
+		return Activity{}, false
+	}
+	return *a, true
+}
+
+// LookupFold ищет тип тренировки по названию или псевдониму без учёта
+// регистра; точное совпадение предпочитается.
+func (r *Registry) LookupFold(name string) (Activity, bool) {
+	if a, ok := r.Lookup(name); ok {
+		return a, true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name = strings.TrimSpace(name)
+	for key, a := range r.activities {
+		if strings.EqualFold(key, name) {
+			return *a, true
+		}
+	}
+	return Activity{}, false
+}
+
+// Activities возвращает зарегистрированные типы тренировок, отсортированные по названию.
+func (r *Registry) Activities() []Activity {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var list []Activity
+	for name, a := range r.activities {
+		if name == a.Name {
+			list = append(list, *a)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+var defaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+
+	builtin := []Activity{
+		{Name: Running, Aliases: []string{i18n.In(i18n.EN, Running)}, Kind: spentenergy.Running, ShowPace: true},
+		{Name: Walking, Aliases: []string{i18n.In(i18n.EN, Walking)}, Kind: spentenergy.Walking},
+	}
+	for _, a := range builtin {
+		if err := r.Register(a); err != nil {
+			panic(err)
+		}
+	}
+
+	return r
+}
+
+// DefaultRegistry возвращает реестр, который используется по умолчанию.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register добавляет тип тренировки в реестр по умолчанию.
+func Register(a Activity) error {
+	return defaultRegistry.Register(a)
+}
+
+// Lookup ищет тип тренировки в реестре по умолчанию.
+func Lookup(name string) (Activity, bool) {
+	return defaultRegistry.Lookup(name)
+}
@@ -0,0 +1,137 @@
+package trainings
+
+import (
+	"testing"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/spentenergy"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultRegistry(t *testing.T) {
+	for _, name := range []string{Running, Walking} {
+		a, ok := Lookup(name)
+		require.True(t, ok, "встроенный тип %q должен быть зарегистрирован", name)
+		assert.Equal(t, name, a.Title())
+	}
+
+	_, ok := Lookup("Плавание")
+	assert.False(t, ok)
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+
+	err := r.Register(Activity{
+		Name:        "Скандинавская ходьба",
+		DisplayName: "Скандинавская ходьба с палками",
+		Aliases:     []string{"Nordic walking"},
+		Calories:    spentenergy.WalkingSpentCalories,
+	})
+	require.NoError(t, err)
+
+	a, ok := r.Lookup("Nordic walking")
+	require.True(t, ok, "тип должен находиться по псевдониму")
+	assert.Equal(t, "Скандинавская ходьба", a.Name)
+	assert.Equal(t, "Скандинавская ходьба с палками", a.Title())
+
+	tests := []struct {
+		name     string
+		activity Activity
+	}{
+		{name: "пустое название", activity: Activity{Calories: spentenergy.WalkingSpentCalories}},
+		{name: "нет функции калорий", activity: Activity{Name: "Поход"}},
+		{name: "повтор названия", activity: Activity{Name: "Nordic walking", Calories: spentenergy.WalkingSpentCalories}},
+		{name: "пустой псевдоним", activity: Activity{Name: "Поход", Aliases: []string{" "}, Calories: spentenergy.WalkingSpentCalories}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, r.Register(tt.activity))
+		})
+	}
+
+	require.Len(t, r.Activities(), 1, "после ошибок реестр не должен меняться")
+}
+
+func TestRegistryLookupFold(t *testing.T) {
+	r := DefaultRegistry()
+
+	_, ok := r.Lookup("бег")
+	assert.False(t, ok, "Lookup учитывает регистр")
+
+	for _, name := range []string{"бег", "БЕГ", "running", " Ходьба "} {
+		a, ok := r.LookupFold(name)
+		require.True(t, ok, name)
+		assert.Contains(t, []string{Running, Walking}, a.Name)
+	}
+
+	_, ok = r.LookupFold("плавание")
+	assert.False(t, ok)
+}
+
+func TestTrainingCustomRegistry(t *testing.T) {
+	r := NewRegistry()
+	require.NoError(t, r.Register(Activity{
+		Name:    "Лестница",
+		Aliases: []string{"Stairs"},
+		Calories: func(steps int, weight, height float64, duration time.Duration) (float64, error) {
+			return 100, nil
+		},
+	}))
+
+	training := Training{
+		Steps:        1000,
+		TrainingType: "Stairs",
+		Duration:     time.Hour,
+		Personal:     personaldata.Personal{Weight: 75, Height: 1.75},
+		Registry:     r,
+	}
+
+	got, err := training.ActionInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "Тип тренировки: Лестница\nДлительность: 1.00 ч.\nДистанция: 0.79 км.\nСкорость: 0.79 км/ч\nСожгли калорий: 100.00\n", got)
+
+	training.TrainingType = Running
+	_, err = training.ActionInfo()
+	assert.Error(t, err, "тип из реестра по умолчанию не должен находиться в собственном реестре")
+}
+
+func TestTrainingEstimator(t *testing.T) {
+	training := Training{
+		Steps:        20000,
+		TrainingType: Running,
+		Duration:     time.Hour,
+		Personal:     personaldata.Personal{Weight: 75, Height: 1.75},
+		Estimator:    spentenergy.METEstimator{},
+	}
+
+	got, err := training.ActionInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 15.75 км.\nСкорость: 15.75 км/ч\nСожгли калорий: 960.00\nТемп: 3:49 мин/км\n", got)
+}
+
+func TestTrainingLocalizedType(t *testing.T) {
+	defer i18n.SetLocale(i18n.Current())
+	i18n.SetLocale(i18n.EN)
+
+	training := Training{Personal: personaldata.Personal{Weight: 75, Height: 1.75}}
+	require.NoError(t, training.Parse("6000,Running,1h00m"))
+
+	got, err := training.ActionInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "Training type: Running\nDuration: 1.00 h.\nDistance: 4.72 km.\nSpeed: 4.72 km/h\nCalories burned: 354.38\nPace: 12:42 min/km\n", got)
+
+	require.NoError(t, training.Parse("6000,Бег,1h00m"))
+	got, err = training.ActionInfo()
+	require.NoError(t, err)
+	assert.Contains(t, got, "Training type: Running\n", "русское название тоже должно распознаваться")
+
+	r, err := training.Result()
+	require.NoError(t, err)
+	assert.Equal(t, Running, r.Type, "в результате — каноническое название, не зависящее от языка")
+	assert.Empty(t, r.Title)
+}
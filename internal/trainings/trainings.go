@@ -1,87 +1,331 @@
 package trainings
 
 import (
-	"fmt"
 	"log"
 	"strconv"
 	"strings"
 	"time"
 
+	"FINAL-PROJECT-5/internal/i18n"
 	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/report"
 	"FINAL-PROJECT-5/internal/spentenergy"
 )
 
 type Training struct {
+	Time         time.Time // время начала тренировки; нулевое, если в записи его нет.
 	Steps        int
 	TrainingType string
 	Duration     time.Duration
+	HeartRate    int            // средний пульс, уд/мин; 0 — пульс не измерен.
+	Laps         []report.Split // круги; пусто, если в записи их нет.
+
+	// Distance — измеренная дистанция, км (например, по треку GPS);
+	// 0 — дистанция оценивается по шагам и росту.
+	Distance float64
+	// Elapsed — общее время от начала до конца тренировки вместе
+	// с остановками; 0 — совпадает с Duration. Duration в этом случае —
+	// время в движении.
+	Elapsed time.Duration
+	// ElevationGain и ElevationLoss — набор и сброс высоты, м.
+	ElevationGain, ElevationLoss float64
+	// DeviceCalories — калории по данным устройства, ккал; 0 — устройство
+	// их не сообщило. Если они известны, в отчёт идут они, а расчётная
+	// оценка приводится рядом для сравнения.
+	DeviceCalories float64
+
 	personaldata.Personal
+
+	// Registry — реестр типов тренировок; если nil, используется DefaultRegistry().
+	Registry *Registry
+	// Estimator — модель расчёта калорий для типов тренировок с заданным Kind;
+	// если nil, используется spentenergy.DefaultEstimator. При известном пульсе
+	// и заполненных возрасте и поле калории считаются по пульсу.
+	Estimator spentenergy.Estimator
+	// ParseOptions — режим разбора записей. В режиме по умолчанию, как
+	// и прежде, все поля очищаются от пробелов по краям.
+	ParseOptions record.ParseOptions
+	// Plausibility — правила проверки правдоподобия; если nil, запись не проверяется.
+	Plausibility *plausibility.Rules
+
+	typeColumn int // номер поля с типом тренировки в разобранной записи.
 }
 
+// Parse разбирает запись вида "[время,]шаги,тип,продолжительность[,ключ=значение...]"
+// в режиме ParseOptions. Ошибка имеет тип *record.FieldError; её код
+// проверяется через errors.Is. Тип тренировки проверяется по реестру
+// только в Result.
 func (t *Training) Parse(datastring string) (err error) {
-	parts := strings.Split(datastring, ",")
-
-	if len(parts) != 3 {
-		log.Println("Ошибка: нехватка данных")
-		return fmt.Errorf("нехватка данных")
+	recordTime, parts, column, err := t.ParseOptions.Split(datastring)
+	if err != nil {
+		return err
 	}
 
-	steps, err := strconv.Atoi(strings.TrimSpace(parts[0]))
-	if err != nil {
-		log.Println("Ошибка при преобразовании количества шагов:", err)
-		return fmt.Errorf("неверный формат количества шагов")
+	if len(parts) < 3 {
+		log.Println(i18n.T("Ошибка: нехватка данных"))
+		return record.NewFieldError("", 0, datastring, record.ErrMissingFields, nil)
+	}
+	for i := range parts {
+		parts[i] = t.ParseOptions.TrimDefault(parts[i])
 	}
 
-	if steps <= 0 {
-		return fmt.Errorf("количество шагов должно быть больше нуля")
+	steps, err := t.ParseOptions.Steps(parts[0], column)
+	if err != nil {
+		return err
 	}
 
+	t.Time = recordTime
 	t.Steps = steps
 
-	t.TrainingType = strings.TrimSpace(parts[1])
+	if err := t.parseType(parts[1], column+1); err != nil {
+		return err
+	}
 
-	durationStr := strings.TrimSpace(parts[2])
-	duration, err := time.ParseDuration(durationStr)
+	duration, err := t.ParseOptions.Duration(parts[2], column+2)
 	if err != nil {
-		log.Println("Ошибка при преобразовании продолжительности:", err)
-		return fmt.Errorf("неверный формат продолжительности")
+		return err
 	}
 
-	if duration <= 0 {
-		return fmt.Errorf("количество шагов должно быть больше нуля")
+	t.Duration = duration
+
+	if err := t.parseExtra(parts[3:], column+3); err != nil {
+		return err
 	}
 
-	t.Duration = duration
+	return nil
+}
+
+// parseType разбирает тип тренировки из поля с номером column. В нестрогом
+// режиме тип ищется в реестре без учёта регистра и заменяется названием
+// из реестра; неизвестный тип сохраняется как есть.
+func (t *Training) parseType(value string, column int) error {
+	t.TrainingType = value
+	t.typeColumn = column
 
+	if !t.ParseOptions.Lenient() {
+		if value != strings.TrimSpace(value) {
+			return record.NewFieldError(record.FieldType, column, value, record.ErrInvalidType,
+				i18n.Errorf("тип тренировки не должен содержать пробелов в начале или в конце"))
+		}
+		return nil
+	}
+
+	if a, ok := t.registry().LookupFold(value); ok {
+		t.TrainingType = a.Name
+	}
 	return nil
 }
 
 func (t Training) ActionInfo() (string, error) {
+	r, err := t.Result()
+	if err != nil {
+		return "", err
+	}
+	return report.Text(r), nil
+}
 
-	distance := spentenergy.Distance(t.Steps, t.Personal.Height)
-	averageSpeed := spentenergy.MeanSpeed(t.Steps, t.Personal.Height, t.Duration)
+// Result возвращает рассчитанные показатели тренировки.
+// Параметры тела берутся на момент записи; см. personaldata.Personal.AsOf.
+// Неправдоподобная запись отклоняется ошибкой plausibility.Violation
+// или принимается с предупреждениями в зависимости от Plausibility.
+func (t Training) Result() (report.Result, error) {
+	person := t.Personal.AsOf(t.Time)
+
+	distance := spentenergy.Distance(t.Steps, person.Height)
+	averageSpeed := spentenergy.MeanSpeed(t.Steps, person.Height, t.Duration)
+	if t.Distance > 0 {
+		distance = t.Distance
+		averageSpeed = 0
+		if t.Duration > 0 {
+			averageSpeed = distance / t.Duration.Hours()
+		}
+	}
 
 	if averageSpeed < 0 {
-		return "", fmt.Errorf("недопустимая средняя скорость")
+		return report.Result{}, i18n.Errorf("недопустимая средняя скорость")
 	}
 
-	var calories float64
-	var err error
+	activity, ok := t.Activity()
+	if !ok {
+		return report.Result{}, record.NewFieldError(record.FieldType, t.typeColumn, t.TrainingType, record.ErrUnknownType,
+			i18n.Errorf("неизвестный тип тренировки: %s", t.TrainingType))
+	}
 
-	switch t.TrainingType {
-	case "Бег":
-		calories, err = spentenergy.RunningSpentCalories(t.Steps, t.Personal.Weight, t.Personal.Height, t.Duration)
-	case "Ходьба":
-		calories, err = spentenergy.WalkingSpentCalories(t.Steps, t.Personal.Weight, t.Personal.Height, t.Duration)
-	default:
-		return "", fmt.Errorf("неизвестный тип тренировки: %s", t.TrainingType)
+	// Калории устройства позволяют обойтись без расчёта, если для него
+	// не хватает данных (например, шагов в треке без каденса).
+	calories, err := t.calories(activity, person)
+	if err != nil && t.DeviceCalories <= 0 {
+		return report.Result{}, i18n.Errorf("ошибка при расчете калорий: %v", err)
+	}
+
+	r := report.Result{
+		Kind:      report.KindTraining,
+		Time:      t.Time,
+		Type:      activity.Name,
+		Title:     activity.DisplayName,
+		Steps:     t.Steps,
+		Duration:  t.Duration,
+		Distance:  distance,
+		Speed:     averageSpeed,
+		Calories:  calories,
+		HeartRate: t.HeartRate,
+		Splits:    t.Laps,
+
+		Elapsed:       t.Elapsed,
+		ElevationGain: t.ElevationGain,
+		ElevationLoss: t.ElevationLoss,
+	}
+	if t.DeviceCalories > 0 {
+		r.Calories, r.EstimatedCalories = t.DeviceCalories, calories
+	}
+	if activity.ShowPace || len(t.Laps) > 0 {
+		r.Pace = spentenergy.Pace(averageSpeed)
 	}
 
+	r.Warnings, err = t.Plausibility.Check(plausibility.Record{
+		Activity: activity.Name,
+		Kind:     activity.Kind,
+		Steps:    r.Steps,
+		Duration: r.Duration,
+		Speed:    r.Speed,
+	})
 	if err != nil {
-		return "", fmt.Errorf("ошибка при расчете калорий: %v", err)
+		return report.Result{}, err
 	}
 
-	return fmt.Sprintf("Тип тренировки: %s\nДлительность: %.2f ч.\nДистанция: %.2f км.\nСкорость: %.2f км/ч\nСожгли калорий: %.2f\n",
-		t.TrainingType, t.Duration.Hours(), distance, averageSpeed, calories), nil
+	return r, nil
+}
+
+// Activity возвращает тип тренировки записи из реестра.
+func (t Training) Activity() (Activity, bool) {
+	return t.registry().Lookup(t.TrainingType)
+}
+
+func (t Training) registry() *Registry {
+	if t.Registry != nil {
+		return t.Registry
+	}
+	return DefaultRegistry()
+}
+
+// calories рассчитывает калории: для типов с Kind — через Estimator,
+// для остальных — собственной функцией типа. Если известен пульс,
+// расчёт выполняется по пульсу, а эти модели используются как запасные.
+func (t Training) calories(a Activity, person personaldata.Personal) (float64, error) {
+	var estimator spentenergy.Estimator
+	switch {
+	case a.Kind == "":
+		estimator = spentenergy.EstimatorFunc(func(in spentenergy.Input, p personaldata.Personal) (float64, error) {
+			return a.Calories(in.Steps, p.Weight, p.Height, in.Duration)
+		})
+	case t.Estimator != nil:
+		estimator = t.Estimator
+	default:
+		estimator = spentenergy.DefaultEstimator
+	}
+
+	if t.HeartRate > 0 {
+		estimator = spentenergy.HeartRateEstimator{Fallback: estimator}
+	}
+
+	return estimator.Calories(spentenergy.Input{
+		Activity:  a.Kind,
+		Steps:     t.Steps,
+		Duration:  t.Duration,
+		Distance:  t.Distance,
+		HeartRate: float64(t.HeartRate),
+	}, person)
+}
+
+// Границы допустимого среднего пульса, уд/мин.
+const (
+	MinHeartRate = 30
+	MaxHeartRate = 250
+)
+
+// parseExtra разбирает необязательные поля записи вида ключ=значение,
+// которые следуют за продолжительностью:
+//
+//	hr   — средний пульс, уд/мин;
+//	laps — круги через "|", каждый в виде [дистанция_км@]время,
+//	       например laps=5m10s|4:55|0.5@2m20s; без дистанции круг
+//	       считается равным одному километру, время — в любом из
+//	       форматов record.ParseDuration.
+//
+// В нестрогом режиме пустые поля, поля без "=" и неизвестные ключи
+// пропускаются. column — номер первого дополнительного поля в записи.
+func (t *Training) parseExtra(fields []string, column int) error {
+	t.HeartRate = 0
+	t.Laps = nil
+
+	lenient := t.ParseOptions.Lenient()
+	for i, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if lenient && (!ok || !knownExtra(key)) {
+			continue
+		}
+		if !ok || field != strings.TrimSpace(field) {
+			return record.NewFieldError("", column+i, field, record.ErrInvalidExtra,
+				i18n.Errorf("неверный формат дополнительного поля: %q", field))
+		}
+
+		switch key {
+		case "hr":
+			hr, err := strconv.Atoi(value)
+			if err != nil {
+				return record.NewFieldError(key, column+i, value, record.ErrInvalidExtra,
+					i18n.Errorf("неверный формат пульса: %q", value))
+			}
+			if hr < MinHeartRate || hr > MaxHeartRate {
+				return record.NewFieldError(key, column+i, value, record.ErrInvalidExtra,
+					i18n.Errorf("пульс должен быть от %d до %d уд/мин", MinHeartRate, MaxHeartRate))
+			}
+			t.HeartRate = hr
+		case "laps":
+			laps, err := parseLaps(value)
+			if err != nil {
+				return record.NewFieldError(key, column+i, value, record.ErrInvalidExtra, err)
+			}
+			t.Laps = laps
+		default:
+			return record.NewFieldError(key, column+i, value, record.ErrInvalidExtra,
+				i18n.Errorf("неизвестное дополнительное поле: %q", key))
+		}
+	}
+
+	return nil
+}
+
+// knownExtra сообщает, известен ли ключ дополнительного поля.
+func knownExtra(key string) bool {
+	return key == "hr" || key == "laps"
+}
+
+// parseLaps разбирает список кругов из поля laps.
+func parseLaps(value string) ([]report.Split, error) {
+	var laps []report.Split
+
+	for _, lap := range strings.Split(value, "|") {
+		distance := 1.0
+		durationStr := lap
+
+		if d, rest, ok := strings.Cut(lap, "@"); ok {
+			v, err := strconv.ParseFloat(d, 64)
+			if err != nil || v <= 0 {
+				return nil, i18n.Errorf("неверная дистанция круга: %q", lap)
+			}
+			distance, durationStr = v, rest
+		}
+
+		duration, err := record.ParseDuration(durationStr)
+		if err != nil || duration <= 0 {
+			return nil, i18n.Errorf("неверное время круга: %q", lap)
+		}
+
+		laps = append(laps, report.Split{Distance: distance, Duration: duration})
+	}
 
+	return laps, nil
 }
@@ -5,6 +5,9 @@ import (
 	"time"
 
 	"FINAL-PROJECT-5/internal/personaldata"
+	"FINAL-PROJECT-5/internal/plausibility"
+	"FINAL-PROJECT-5/internal/record"
+	"FINAL-PROJECT-5/internal/report"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -197,12 +200,28 @@ func (suite *SpentCaloriesTestSuite) TestParseTraining() {
 			wantErr:      true,
 		},
 		{
-			name:         "неверная продолжительность - пропущена единица измерения",
+			name:         "продолжительность без единицы измерения - минуты",
 			input:        "678,Ходьба,30",
-			wantSteps:    0,
-			wantType:     "",
-			wantDuration: 0,
-			wantErr:      true,
+			wantSteps:    678,
+			wantType:     "Ходьба",
+			wantDuration: 30 * time.Minute,
+			wantErr:      false,
+		},
+		{
+			name:         "продолжительность в формате ММ:СС",
+			input:        "678,Бег,42:30",
+			wantSteps:    678,
+			wantType:     "Бег",
+			wantDuration: 42*time.Minute + 30*time.Second,
+			wantErr:      false,
+		},
+		{
+			name:         "продолжительность в формате ISO 8601",
+			input:        "678,Бег,PT1H5M30S",
+			wantSteps:    678,
+			wantType:     "Бег",
+			wantDuration: time.Hour + 5*time.Minute + 30*time.Second,
+			wantErr:      false,
 		},
 	}
 
@@ -246,7 +265,7 @@ func (suite *SpentCaloriesTestSuite) TestActionInfo() {
 			input:   "6000,Бег,1h00m",
 			weight:  75.0,
 			height:  1.75,
-			want:    "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 4.72 км.\nСкорость: 4.72 км/ч\nСожгли калорий: 354.38\n",
+			want:    "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 4.72 км.\nСкорость: 4.72 км/ч\nСожгли калорий: 354.38\nТемп: 12:42 мин/км\n",
 			wantErr: false,
 		},
 		{
@@ -262,7 +281,7 @@ func (suite *SpentCaloriesTestSuite) TestActionInfo() {
 			input:   "20000,Бег,1h00m",
 			weight:  75.0,
 			height:  1.75,
-			want:    "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 15.75 км.\nСкорость: 15.75 км/ч\nСожгли калорий: 1181.25\n",
+			want:    "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 15.75 км.\nСкорость: 15.75 км/ч\nСожгли калорий: 1181.25\nТемп: 3:49 мин/км\n",
 			wantErr: false,
 		},
 		{
@@ -278,7 +297,7 @@ func (suite *SpentCaloriesTestSuite) TestActionInfo() {
 			input:   "6000,Бег,1h00m",
 			weight:  60.0,
 			height:  1.75,
-			want:    "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 4.72 км.\nСкорость: 4.72 км/ч\nСожгли калорий: 283.50\n",
+			want:    "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 4.72 км.\nСкорость: 4.72 км/ч\nСожгли калорий: 283.50\nТемп: 12:42 мин/км\n",
 			wantErr: false,
 		},
 		{
@@ -294,7 +313,7 @@ func (suite *SpentCaloriesTestSuite) TestActionInfo() {
 			input:   "3000,Бег,30m",
 			weight:  75.0,
 			height:  1.75,
-			want:    "Тип тренировки: Бег\nДлительность: 0.50 ч.\nДистанция: 2.36 км.\nСкорость: 4.72 км/ч\nСожгли калорий: 177.19\n",
+			want:    "Тип тренировки: Бег\nДлительность: 0.50 ч.\nДистанция: 2.36 км.\nСкорость: 4.72 км/ч\nСожгли калорий: 177.19\nТемп: 12:42 мин/км\n",
 			wantErr: false,
 		},
 		{
@@ -336,3 +355,329 @@ func (suite *SpentCaloriesTestSuite) TestActionInfo() {
 		})
 	}
 }
+
+func (suite *SpentCaloriesTestSuite) TestParseHeartRate() {
+	tests := []struct {
+		name    string
+		input   string
+		wantHR  int
+		wantErr bool
+	}{
+		{name: "пульс указан", input: "6000,Бег,1h00m,hr=150", wantHR: 150},
+		{name: "пульс не указан", input: "6000,Бег,1h00m", wantHR: 0},
+		{name: "пульс - не число", input: "6000,Бег,1h00m,hr=abc", wantErr: true},
+		{name: "пульс вне диапазона", input: "6000,Бег,1h00m,hr=400", wantErr: true},
+		{name: "неизвестное поле", input: "6000,Бег,1h00m,foo=1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			training := &Training{HeartRate: 99}
+			err := training.Parse(tt.input)
+			if tt.wantErr {
+				require.Error(suite.T(), err, "для ввода %q ожидалась ошибка", tt.input)
+				return
+			}
+			require.NoError(suite.T(), err)
+			assert.Equal(suite.T(), tt.wantHR, training.HeartRate, "пульс из прошлой записи не должен сохраняться")
+		})
+	}
+}
+
+func (suite *SpentCaloriesTestSuite) TestParseErrors() {
+	tests := []struct {
+		input   string
+		code    error
+		field   string
+		column  int
+		value   string
+		message string
+	}{
+		{"6000,Бег", record.ErrMissingFields, "", 0, "6000,Бег", "нехватка данных"},
+		{"abc,Бег,1h", record.ErrInvalidSteps, record.FieldSteps, 1, "abc", "неверный формат количества шагов"},
+		{"-5,Бег,1h", record.ErrNotPositive, record.FieldSteps, 1, "-5", "количество шагов должно быть больше нуля"},
+		{"6000,Бег,час", record.ErrInvalidDuration, record.FieldDuration, 3, "час", "неверный формат продолжительности"},
+		{"2024-05-01T07:30:00Z,6000,Бег,0h", record.ErrNotPositive, record.FieldDuration, 4, "0h", "продолжительность должна быть больше нуля"},
+		{"6000,Бег,1h,hr=400", record.ErrInvalidExtra, "hr", 4, "400", "пульс должен быть от 30 до 250 уд/мин"},
+		{"6000,Бег,1h,hr=150,laps=0@1m", record.ErrInvalidExtra, "laps", 5, "0@1m", `неверная дистанция круга: "0@1m"`},
+		{"6000,Бег,1h,foo", record.ErrInvalidExtra, "", 4, "foo", `неверный формат дополнительного поля: "foo"`},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.input, func() {
+			err := (&Training{}).Parse(tt.input)
+			assert.ErrorIs(suite.T(), err, tt.code)
+
+			var fe *record.FieldError
+			require.ErrorAs(suite.T(), err, &fe)
+			assert.Equal(suite.T(), tt.field, fe.Field)
+			assert.Equal(suite.T(), tt.column, fe.Column)
+			assert.Equal(suite.T(), tt.value, fe.Value)
+			assert.EqualError(suite.T(), err, tt.message)
+		})
+	}
+}
+
+func (suite *SpentCaloriesTestSuite) TestParseModes() {
+	tests := []struct {
+		input    string
+		def      error // код ошибки в режиме по умолчанию; nil — запись разбирается
+		strict   error // код ошибки в строгом режиме; nil — запись разбирается
+		steps    int
+		typeName string
+	}{
+		{input: "6000,Бег,1h", steps: 6000, typeName: "Бег"},
+		{input: " 6000,Бег,1h", strict: record.ErrInvalidSteps, steps: 6000, typeName: "Бег"},
+		{input: "6 000,бег,1h", def: record.ErrInvalidSteps, strict: record.ErrInvalidSteps, steps: 6000, typeName: "Бег"},
+		{input: "6000, Ходьба ,1h", strict: record.ErrInvalidType, steps: 6000, typeName: "Ходьба"},
+		{input: "6000,Бег, 1h", strict: record.ErrInvalidDuration, steps: 6000, typeName: "Бег"},
+		{input: "6000,Бег,1h,", def: record.ErrInvalidExtra, strict: record.ErrInvalidExtra, steps: 6000, typeName: "Бег"},
+		{input: "6000,Бег,1h,cadence=170,note", def: record.ErrInvalidExtra, strict: record.ErrInvalidExtra, steps: 6000, typeName: "Бег"},
+		{input: "6000,Бег,1h, hr=150", strict: record.ErrInvalidExtra, steps: 6000, typeName: "Бег"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.input, func() {
+			for _, m := range []struct {
+				mode record.Mode
+				want error
+				name string
+			}{
+				{record.Default, tt.def, "режим по умолчанию"},
+				{record.Strict, tt.strict, "строгий режим"},
+			} {
+				training := &Training{ParseOptions: record.ParseOptions{Mode: m.mode}}
+				err := training.Parse(tt.input)
+				if m.want != nil {
+					assert.ErrorIs(suite.T(), err, m.want, m.name)
+				} else {
+					assert.NoError(suite.T(), err, m.name)
+				}
+			}
+
+			training := &Training{ParseOptions: record.ParseOptions{Mode: record.Lenient}}
+			require.NoError(suite.T(), training.Parse(tt.input), "нестрогий режим")
+			assert.Equal(suite.T(), tt.steps, training.Steps)
+			assert.Equal(suite.T(), tt.typeName, training.TrainingType)
+		})
+	}
+
+	training := &Training{ParseOptions: record.ParseOptions{Mode: record.Lenient}}
+	require.NoError(suite.T(), training.Parse("6000,бег,1h, hr=150 ,foo=1"))
+	assert.Equal(suite.T(), 150, training.HeartRate, "известные поля разбираются и в нестрогом режиме")
+	assert.Error(suite.T(), training.Parse("6000,бег,1h,hr=abc"), "неверное значение известного поля — ошибка и в нестрогом режиме")
+	require.NoError(suite.T(), training.Parse("6000,плавание,1h"))
+	assert.Equal(suite.T(), "плавание", training.TrainingType, "неизвестный тип сохраняется как есть")
+}
+
+func (suite *SpentCaloriesTestSuite) TestResultUnknownType() {
+	training := &Training{Personal: personaldata.Personal{Weight: 75, Height: 1.75}}
+	require.NoError(suite.T(), training.Parse("2024-05-01T07:30:00Z,6000,Плавание,1h"))
+
+	_, err := training.Result()
+	assert.ErrorIs(suite.T(), err, record.ErrUnknownType)
+	var fe *record.FieldError
+	require.ErrorAs(suite.T(), err, &fe)
+	assert.Equal(suite.T(), record.FieldType, fe.Field)
+	assert.Equal(suite.T(), 3, fe.Column)
+	assert.Equal(suite.T(), "Плавание", fe.Value)
+}
+
+func (suite *SpentCaloriesTestSuite) TestResultPlausibility() {
+	rules := plausibility.DefaultRules()
+	person := personaldata.Personal{Weight: 75, Height: 1.75}
+
+	training := &Training{Personal: person, Plausibility: &rules}
+	require.NoError(suite.T(), training.Parse("14000,Ходьба,1h"))
+	got, err := training.Result()
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), got.Warnings, 1)
+	assert.Equal(suite.T(), plausibility.Violation{Check: plausibility.Speed, Value: got.Speed, Limit: 10}, got.Warnings[0])
+
+	text, err := training.ActionInfo()
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), text, "Предупреждение: неправдоподобная скорость: 11.03 км/ч, допускается не больше 10.00\n")
+
+	walking := rules.Merge(plausibility.Rules{MaxSpeed: map[string]float64{Walking: 15}})
+	training.Plausibility = &walking
+	got, err = training.Result()
+	require.NoError(suite.T(), err)
+	assert.Empty(suite.T(), got.Warnings, "предел для типа тренировки важнее предела для вида активности")
+
+	training = &Training{Personal: person, Plausibility: &rules}
+	require.NoError(suite.T(), training.Parse("6000,Бег,30h"))
+	_, err = training.Result()
+	assert.ErrorIs(suite.T(), err, plausibility.ErrImplausible)
+}
+
+func (suite *SpentCaloriesTestSuite) TestResultMeasuredDistance() {
+	training := Training{
+		TrainingType:  Running,
+		Steps:         6000,
+		Duration:      time.Hour,
+		Distance:      10,
+		Elapsed:       70 * time.Minute,
+		ElevationGain: 50,
+		ElevationLoss: 40,
+		Personal:      personaldata.Personal{Weight: 75, Height: 1.75},
+	}
+
+	got, err := training.Result()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 10.0, got.Distance, "измеренная дистанция важнее оценки по шагам")
+	assert.Equal(suite.T(), 10.0, got.Speed)
+	assert.InDelta(suite.T(), 750, got.Calories, 1e-9)
+	assert.Equal(suite.T(), 6*time.Minute, got.Pace)
+	assert.Equal(suite.T(), 70*time.Minute, got.Elapsed)
+
+	text, err := training.ActionInfo()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 10.00 км.\nСкорость: 10.00 км/ч\nСожгли калорий: 750.00\n"+
+		"Общее время: 1.17 ч.\nНабор высоты: 50 м, сброс: 40 м\nТемп: 6:00 мин/км\n", text)
+
+	training.Steps = 0
+	got, err = training.Result()
+	require.NoError(suite.T(), err, "для трека без шагов калории считаются по дистанции")
+	assert.InDelta(suite.T(), 750, got.Calories, 1e-9)
+}
+
+func (suite *SpentCaloriesTestSuite) TestResultDeviceCalories() {
+	training := Training{
+		TrainingType:   Running,
+		Duration:       time.Hour,
+		Distance:       10,
+		DeviceCalories: 700,
+		Personal:       personaldata.Personal{Weight: 75, Height: 1.75},
+	}
+
+	got, err := training.Result()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 700.0, got.Calories, "калории устройства важнее расчёта")
+	assert.InDelta(suite.T(), 750, got.EstimatedCalories, 1e-9)
+
+	text, err := training.ActionInfo()
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), text, "Сожгли калорий: 700.00\nКалории — по данным устройства; по расчёту: 750.00 (разница -7%)\n")
+
+	training.Distance = 0
+	got, err = training.Result()
+	require.NoError(suite.T(), err, "без шагов и дистанции остаются калории устройства")
+	assert.Equal(suite.T(), 700.0, got.Calories)
+	assert.Zero(suite.T(), got.EstimatedCalories)
+
+	training.DeviceCalories = 0
+	_, err = training.Result()
+	assert.Error(suite.T(), err)
+}
+
+func (suite *SpentCaloriesTestSuite) TestActionInfoHeartRate() {
+	training := &Training{
+		Personal: personaldata.Personal{Weight: 75, Height: 1.75, Age: 30, Sex: personaldata.SexMale},
+	}
+	require.NoError(suite.T(), training.Parse("6000,Бег,1h00m,hr=150"))
+
+	got, err := training.ActionInfo()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Тип тренировки: Бег\nДлительность: 1.00 ч.\nДистанция: 4.72 км.\nСкорость: 4.72 км/ч\nСожгли калорий: 867.58\nСредний пульс: 150 уд/мин\nТемп: 12:42 мин/км\n", got)
+
+	training.Personal.Age = 0
+	got, err = training.ActionInfo()
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), got, "Сожгли калорий: 354.38\n", "без возраста калории считаются по скорости")
+}
+
+func (suite *SpentCaloriesTestSuite) TestParseTime() {
+	training := &Training{}
+	require.NoError(suite.T(), training.Parse("2024-05-01T18:00:00+03:00,3456,Ходьба,3h00m,hr=120"))
+	assert.True(suite.T(), time.Date(2024, 5, 1, 15, 0, 0, 0, time.UTC).Equal(training.Time))
+	assert.Equal(suite.T(), 3456, training.Steps)
+	assert.Equal(suite.T(), "Ходьба", training.TrainingType)
+	assert.Equal(suite.T(), 120, training.HeartRate)
+
+	require.NoError(suite.T(), training.Parse("678,Бег,5m"))
+	assert.True(suite.T(), training.Time.IsZero(), "время из прошлой записи не должно сохраняться")
+
+	assert.Error(suite.T(), training.Parse("2024-05-01 18:00,3456,Ходьба,3h00m"))
+}
+
+func (suite *SpentCaloriesTestSuite) TestParseLaps() {
+	tests := []struct {
+		name     string
+		input    string
+		wantLaps []report.Split
+		wantErr  bool
+	}{
+		{
+			name:  "круги по километру",
+			input: "3000,Бег,10m,laps=5m10s|4m50s",
+			wantLaps: []report.Split{
+				{Distance: 1, Duration: 5*time.Minute + 10*time.Second},
+				{Distance: 1, Duration: 4*time.Minute + 50*time.Second},
+			},
+		},
+		{
+			name:     "круг с дистанцией",
+			input:    "3000,Бег,10m,laps=0.4@1m30s",
+			wantLaps: []report.Split{{Distance: 0.4, Duration: 90 * time.Second}},
+		},
+		{
+			name:     "время кругов в формате ММ:СС",
+			input:    "3000,Бег,10m,laps=5:10|0.5@2:20",
+			wantLaps: []report.Split{{Distance: 1, Duration: 5*time.Minute + 10*time.Second}, {Distance: 0.5, Duration: 140 * time.Second}},
+		},
+		{name: "без кругов", input: "3000,Бег,10m"},
+		{name: "неверное время круга", input: "3000,Бег,10m,laps=5m|fast", wantErr: true},
+		{name: "нулевая дистанция круга", input: "3000,Бег,10m,laps=0@5m", wantErr: true},
+		{name: "отрицательное время круга", input: "3000,Бег,10m,laps=-5m", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			training := &Training{Laps: []report.Split{{Distance: 9, Duration: time.Hour}}}
+			err := training.Parse(tt.input)
+			if tt.wantErr {
+				require.Error(suite.T(), err, "для ввода %q ожидалась ошибка", tt.input)
+				return
+			}
+			require.NoError(suite.T(), err)
+			assert.Equal(suite.T(), tt.wantLaps, training.Laps, "круги из прошлой записи не должны сохраняться")
+		})
+	}
+}
+
+func (suite *SpentCaloriesTestSuite) TestActionInfoLaps() {
+	training := &Training{Personal: personaldata.Personal{Weight: 75, Height: 1.75}}
+	require.NoError(suite.T(), training.Parse("6000,Ходьба,1h00m,laps=5m10s|4m50s"))
+
+	r, err := training.Result()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 761904761904*time.Nanosecond, r.Pace.Truncate(time.Nanosecond), "темп выводится, если есть круги")
+	assert.Equal(suite.T(), 1, r.FastestSplit())
+
+	got, err := training.ActionInfo()
+	require.NoError(suite.T(), err)
+	assert.Contains(suite.T(), got, "Круги:\n  1. 1.00 км за 5:10, темп 5:10 мин/км\n")
+	assert.Contains(suite.T(), got, "Негативный сплит: да\n")
+}
+
+func (suite *SpentCaloriesTestSuite) TestResultUsesMeasurementsAtRecordTime() {
+	person := personaldata.Personal{
+		Weight: 70,
+		Height: 1.75,
+		Measurements: []personaldata.Measurement{
+			{Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Weight: 90, Height: 1.80},
+			{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Weight: 80},
+		},
+	}
+
+	training := &Training{Personal: person}
+	require.NoError(suite.T(), training.Parse("2022-06-01T10:00:00Z,3000,Бег,30m"))
+	got, err := training.Result()
+	require.NoError(suite.T(), err)
+
+	want, err := Training{Steps: 3000, TrainingType: "Бег", Duration: 30 * time.Minute,
+		Personal: personaldata.Personal{Weight: 90, Height: 1.80}}.Result()
+	require.NoError(suite.T(), err)
+	assert.InDelta(suite.T(), want.Calories, got.Calories, 1e-9)
+	assert.InDelta(suite.T(), want.Distance, got.Distance, 1e-9, "дистанция считается по росту на момент записи")
+}
@@ -0,0 +1,255 @@
+package units
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"FINAL-PROJECT-5/internal/i18n"
+)
+
+// System — система единиц измерения. Расчёты всегда ведутся в метрической
+// системе; System влияет только на ввод и вывод значений.
+type System string
+
+const (
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+// Systems — поддерживаемые системы единиц.
+var Systems = []System{Metric, Imperial}
+
+// Коэффициенты перевода.
+const (
+	kmInMile   = 1.609344
+	kgInPound  = 0.45359237
+	mInInch    = 0.0254
+	inchInFoot = 12
+)
+
+// current хранит текущую систему; пока SetSystem не вызывался, он пуст
+// и используется Metric.
+var current atomic.Value
+
+// SetSystem устанавливает систему единиц для вывода.
+func SetSystem(s System) {
+	current.Store(s)
+}
+
+// Current возвращает текущую систему единиц.
+func Current() System {
+	if s, ok := current.Load().(System); ok {
+		return s
+	}
+	return Metric
+}
+
+// ParseSystem распознаёт систему единиц по названию без учёта регистра.
+func ParseSystem(s string) (System, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "metric", "si":
+		return Metric, nil
+	case "imperial", "us":
+		return Imperial, nil
+	}
+	return "", i18n.Errorf("неизвестная система единиц: %s", s)
+}
+
+func KmToMiles(km float64) float64 {
+	return km / kmInMile
+}
+
+func MilesToKm(mi float64) float64 {
+	return mi * kmInMile
+}
+
+func KgToPounds(kg float64) float64 {
+	return kg / kgInPound
+}
+
+func PoundsToKg(lb float64) float64 {
+	return lb * kgInPound
+}
+
+// MetresToFeetInches переводит метры в футы и дюймы.
+func MetresToFeetInches(m float64) (feet int, inches float64) {
+	total := m / mInInch
+	feet = int(total / inchInFoot)
+	return feet, total - float64(feet*inchInFoot)
+}
+
+// FeetInchesToMetres переводит футы и дюймы в метры.
+func FeetInchesToMetres(feet, inches float64) float64 {
+	return (feet*inchInFoot + inches) * mInInch
+}
+
+// Distance переводит дистанцию из километров в единицы системы s
+// и возвращает значение с обозначением единицы.
+func (s System) Distance(km float64) (float64, string) {
+	if s == Imperial {
+		return KmToMiles(km), i18n.T("миль")
+	}
+	return km, i18n.T("км")
+}
+
+// Speed переводит скорость из км/ч в единицы системы s.
+func (s System) Speed(kmh float64) (float64, string) {
+	if s == Imperial {
+		return KmToMiles(kmh), i18n.T("миль/ч")
+	}
+	return kmh, i18n.T("км/ч")
+}
+
+// Weight переводит вес из килограммов в единицы системы s.
+func (s System) Weight(kg float64) (float64, string) {
+	if s == Imperial {
+		return KgToPounds(kg), i18n.T("фунт.")
+	}
+	return kg, i18n.T("кг")
+}
+
+// FormatHeight возвращает рост в единицах системы s, например "1.87 м"
+// или "6 фт 1.6 дюйм".
+func (s System) FormatHeight(m float64) string {
+	if s == Imperial {
+		// Дюймы округляются до выводимой точности до разбиения на футы,
+		// иначе 5 фт 11.96 дюйм выводились бы как «5 фт 12.0 дюйм».
+		total := math.Round(m/mInInch*10) / 10
+		feet := int(total / inchInFoot)
+		return i18n.T("%d фт %.1f дюйм", feet, total-float64(feet*inchInFoot))
+	}
+	return i18n.T("%.2f м", m)
+}
+
+// Elevation переводит перепад высоты из метров в единицы системы s.
+func (s System) Elevation(m float64) (float64, string) {
+	if s == Imperial {
+		return m / mInInch / inchInFoot, i18n.T("фт")
+	}
+	return m, i18n.T("м")
+}
+
+// Pace переводит темп perKm (время на километр) во время на единицу
+// дистанции системы s и возвращает его с обозначением единицы.
+func (s System) Pace(perKm time.Duration) (time.Duration, string) {
+	if s == Imperial {
+		return time.Duration(float64(perKm) * kmInMile), i18n.T("мин/миля")
+	}
+	return perKm, i18n.T("мин/км")
+}
+
+// FormatPace форматирует темп как минуты:секунды, например "5:07".
+func FormatPace(pace time.Duration) string {
+	pace = pace.Round(time.Second)
+	minutes := int(pace / time.Minute)
+	seconds := int((pace % time.Minute) / time.Second)
+	return strconv.Itoa(minutes) + ":" + twoDigits(seconds)
+}
+
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// ParseWeight разбирает вес с необязательной единицей измерения
+// ("84.6", "84.6kg", "186 lb", "186lbs") и возвращает его в килограммах.
+// Число без единицы считается записанным в единицах системы s.
+func ParseWeight(str string, s System) (float64, error) {
+	value, unit := splitUnit(str)
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || !(v > 0) || math.IsInf(v, 0) {
+		return 0, i18n.Errorf("неверный формат веса: %q", str)
+	}
+
+	switch unit {
+	case "kg", "кг":
+		return v, nil
+	case "lb", "lbs", "фунт", "фунт.":
+		return PoundsToKg(v), nil
+	case "":
+		if s == Imperial {
+			return PoundsToKg(v), nil
+		}
+		return v, nil
+	}
+	return 0, i18n.Errorf("неизвестная единица веса: %q", unit)
+}
+
+// ParseHeight разбирает рост с необязательной единицей измерения
+// ("1.87", "1.87m", "187cm", "74in", `6'2"`, "6ft 2in") и возвращает его
+// в метрах. Число без единицы считается записанным в метрах для
+// метрической системы и в дюймах для имперской.
+func ParseHeight(str string, s System) (float64, error) {
+	str = strings.TrimSpace(str)
+	if m, ok := parseFeetInches(str); ok {
+		return m, nil
+	}
+
+	value, unit := splitUnit(str)
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil || !(v > 0) || math.IsInf(v, 0) {
+		return 0, i18n.Errorf("неверный формат роста: %q", str)
+	}
+
+	switch unit {
+	case "m", "м":
+		return v, nil
+	case "cm", "см":
+		return v / 100, nil
+	case "in", `"`:
+		return FeetInchesToMetres(0, v), nil
+	case "ft", "'":
+		return FeetInchesToMetres(v, 0), nil
+	case "":
+		if s == Imperial {
+			return FeetInchesToMetres(0, v), nil
+		}
+		return v, nil
+	}
+	return 0, i18n.Errorf("неизвестная единица роста: %q", unit)
+}
+
+// parseFeetInches разбирает рост в форматах `6'2"`, `6' 2`, "6ft 2in", "6ft2".
+func parseFeetInches(str string) (float64, bool) {
+	s := strings.ToLower(str)
+	s = strings.ReplaceAll(s, "ft", "'")
+	s = strings.ReplaceAll(s, "in", `"`)
+
+	feetStr, rest, ok := strings.Cut(s, "'")
+	if !ok {
+		return 0, false
+	}
+	rest = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(rest), `"`))
+	if rest == "" {
+		return 0, false
+	}
+
+	feet, err := strconv.ParseFloat(strings.TrimSpace(feetStr), 64)
+	if err != nil || feet < 0 {
+		return 0, false
+	}
+	inches, err := strconv.ParseFloat(rest, 64)
+	if err != nil || inches < 0 || inches >= inchInFoot {
+		return 0, false
+	}
+
+	m := FeetInchesToMetres(feet, inches)
+	return m, m > 0 && !math.IsInf(m, 0)
+}
+
+// splitUnit отделяет число от следующей за ним единицы измерения.
+func splitUnit(str string) (value, unit string) {
+	str = strings.TrimSpace(str)
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '+' || r == '-')
+	})
+	if i < 0 {
+		return str, ""
+	}
+	return strings.TrimSpace(str[:i]), strings.ToLower(strings.TrimSpace(str[i:]))
+}
@@ -0,0 +1,129 @@
+package units
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSystem(t *testing.T) {
+	s, err := ParseSystem(" Imperial ")
+	require.NoError(t, err)
+	assert.Equal(t, Imperial, s)
+
+	_, err = ParseSystem("nautical")
+	assert.Error(t, err)
+}
+
+func TestConversions(t *testing.T) {
+	assert.InDelta(t, 6.2137, KmToMiles(10), 1e-4)
+	assert.InDelta(t, 10, MilesToKm(KmToMiles(10)), 1e-9)
+	assert.InDelta(t, 186.5111, KgToPounds(84.6), 1e-4)
+	assert.InDelta(t, 84.6, PoundsToKg(KgToPounds(84.6)), 1e-9)
+
+	feet, inches := MetresToFeetInches(1.87)
+	assert.Equal(t, 6, feet)
+	assert.InDelta(t, 1.622, inches, 1e-3)
+	assert.InDelta(t, 1.8796, FeetInchesToMetres(6, 2), 1e-9)
+}
+
+func TestSystemFormatting(t *testing.T) {
+	d, unit := Metric.Distance(10)
+	assert.Equal(t, 10.0, d)
+	assert.Equal(t, "км", unit)
+
+	d, unit = Imperial.Distance(MilesToKm(3))
+	assert.InDelta(t, 3, d, 1e-9)
+	assert.Equal(t, "миль", unit)
+
+	e, unit := Imperial.Elevation(FeetInchesToMetres(100, 0))
+	assert.InDelta(t, 100, e, 1e-9)
+	assert.Equal(t, "фт", unit)
+
+	assert.Equal(t, "1.87 м", Metric.FormatHeight(1.87))
+	assert.Equal(t, "6 фт 2.0 дюйм", Imperial.FormatHeight(FeetInchesToMetres(6, 2)))
+	assert.Equal(t, "6 фт 0.0 дюйм", Imperial.FormatHeight(FeetInchesToMetres(5, 11.96)), "округление переносит дюймы в футы")
+	assert.Equal(t, "5 фт 11.9 дюйм", Imperial.FormatHeight(FeetInchesToMetres(5, 11.94)))
+}
+
+func TestPace(t *testing.T) {
+	pace, unit := Metric.Pace(5 * time.Minute)
+	assert.Equal(t, 5*time.Minute, pace)
+	assert.Equal(t, "мин/км", unit)
+	assert.Equal(t, "5:00", FormatPace(pace))
+
+	pace, unit = Imperial.Pace(5 * time.Minute)
+	assert.Equal(t, "8:03", FormatPace(pace))
+	assert.Equal(t, "мин/миля", unit)
+
+	assert.Equal(t, "4:07", FormatPace(4*time.Minute+7*time.Second))
+}
+
+func TestParseWeight(t *testing.T) {
+	tests := []struct {
+		input   string
+		system  System
+		want    float64
+		wantErr bool
+	}{
+		{input: "84.6", system: Metric, want: 84.6},
+		{input: "84.6kg", system: Imperial, want: 84.6},
+		{input: "186 lb", system: Metric, want: 84.368},
+		{input: "186", system: Imperial, want: 84.368},
+		{input: "heavy", system: Metric, wantErr: true},
+		{input: "-5kg", system: Metric, wantErr: true},
+		{input: "NaN", system: Metric, wantErr: true},
+		{input: "+Inf kg", system: Metric, wantErr: true},
+		{input: "80 stone", system: Metric, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseWeight(tt.input, tt.system)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-3)
+		})
+	}
+}
+
+func TestParseHeight(t *testing.T) {
+	tests := []struct {
+		input   string
+		system  System
+		want    float64
+		wantErr bool
+	}{
+		{input: "1.87", system: Metric, want: 1.87},
+		{input: "1.87m", system: Imperial, want: 1.87},
+		{input: "187 cm", system: Metric, want: 1.87},
+		{input: `6'2"`, system: Metric, want: 1.8796},
+		{input: "6' 2", system: Metric, want: 1.8796},
+		{input: "6ft 2in", system: Metric, want: 1.8796},
+		{input: "74in", system: Metric, want: 1.8796},
+		{input: "74", system: Imperial, want: 1.8796},
+		{input: "6ft", system: Metric, want: 1.8288},
+		{input: "tall", system: Metric, wantErr: true},
+		{input: "NaN", system: Metric, wantErr: true},
+		{input: "Inf", system: Imperial, wantErr: true},
+		{input: "1e309ft 2in", system: Metric, wantErr: true},
+		{input: "180 furlong", system: Metric, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseHeight(tt.input, tt.system)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-4)
+		})
+	}
+}